@@ -0,0 +1,96 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/cloudy-sky-software/pulschema/pkg"
+)
+
+// runDiff implements the `diff` command.
+func runDiff(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	oldDir := flags.String("old", "", "directory with the schema.json and metadata.json generated from the old spec")
+	newDir := flags.String("new", "", "directory with the schema.json and metadata.json generated from the new spec")
+	asJSON := flags.Bool("json", false, "print the changes as JSON")
+	allowBreaking := flags.Bool("allow-breaking", false, "don't fail if there are breaking changes")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: pulschema diff -old <dir> -new <dir> [-json] [-allow-breaking]\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *oldDir == "" || *newDir == "" {
+		return errors.New("-old and -new are required")
+	}
+
+	oldPkg, oldMetadata, err := loadGeneratedFiles(*oldDir)
+	if err != nil {
+		return err
+	}
+	newPkg, newMetadata, err := loadGeneratedFiles(*newDir)
+	if err != nil {
+		return err
+	}
+
+	changes := pkg.DiffSchemas(oldPkg, newPkg, oldMetadata, newMetadata)
+	if changes == nil {
+		changes = pkg.Changes{}
+	}
+
+	if *asJSON {
+		b, err := json.MarshalIndent(changes, "", "    ")
+		if err != nil {
+			return errors.Wrap(err, "marshaling changes")
+		}
+		fmt.Fprintln(out, string(b))
+	} else {
+		for _, change := range changes {
+			fmt.Fprintln(out, change.String())
+		}
+	}
+
+	if breaking := changes.Breaking(); len(breaking) > 0 && !*allowBreaking {
+		return errors.Errorf("%d breaking change(s) found", len(breaking))
+	}
+
+	return nil
+}
+
+// loadGeneratedFiles reads the package spec and the metadata written
+// by the `gen` command to dir. The metadata is optional.
+func loadGeneratedFiles(dir string) (*pschema.PackageSpec, *pkg.ProviderMetadata, error) {
+	pkgSpec, err := loadPackageSpec(filepath.Join(dir, schemaFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadataPath := filepath.Join(dir, metadataFileName)
+	b, err := os.ReadFile(metadataPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return pkgSpec, nil, nil
+	}
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "reading metadata %s", metadataPath)
+	}
+
+	var metadata pkg.ProviderMetadata
+	if err := json.Unmarshal(b, &metadata); err != nil {
+		return nil, nil, errors.Wrapf(err, "parsing metadata %s", metadataPath)
+	}
+
+	return pkgSpec, &metadata, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudy-sky-software/pulschema/pkg"
+)
+
+// genTestSchema runs the `gen` command for a spec in the pkg
+// testdata and returns the output directory.
+func genTestSchema(t *testing.T, specFileName string) string {
+	outDir := t.TempDir()
+	err := runGen([]string{
+		"-spec", filepath.Join("..", "..", "pkg", "testdata", specFileName),
+		"-package", filepath.Join("testdata", "package.yaml"),
+		"-out", outDir,
+	})
+	assert.Nil(t, err)
+	return outDir
+}
+
+func TestDiff(t *testing.T) {
+	oldDir := genTestSchema(t, "diff_old_openapi.yml")
+	newDir := genTestSchema(t, "diff_new_openapi.yml")
+
+	var out bytes.Buffer
+	err := runDiff([]string{"-old", oldDir, "-new", newDir}, &out)
+	assert.ErrorContains(t, err, "breaking change(s) found")
+	assert.Contains(t, out.String(), "breaking [resource-removed] fake-package:dimmers/v2:Dimmer: resource removed")
+	assert.Contains(t, out.String(), "non-breaking [resource-added] fake-package:timers/v2:Timer: resource added")
+
+	out.Reset()
+	err = runDiff([]string{"-old", oldDir, "-new", newDir, "-json", "-allow-breaking"}, &out)
+	assert.Nil(t, err)
+	var changes pkg.Changes
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &changes))
+	assert.True(t, changes.HasBreaking())
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	dir := genTestSchema(t, "diff_old_openapi.yml")
+
+	var out bytes.Buffer
+	err := runDiff([]string{"-old", dir, "-new", dir}, &out)
+	assert.Nil(t, err)
+	assert.Empty(t, out.String())
+}
+
+func TestDiffRequiresOldAndNew(t *testing.T) {
+	err := runDiff([]string{"-old", t.TempDir()}, &bytes.Buffer{})
+	assert.EqualError(t, err, "-old and -new are required")
+}
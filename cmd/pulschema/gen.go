@@ -0,0 +1,203 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/yaml"
+	"github.com/pkg/errors"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/cloudy-sky-software/pulschema/pkg"
+)
+
+const (
+	schemaFileName           = "schema.json"
+	metadataFileName         = "metadata.json"
+	csharpNamespacesFileName = "csharp-namespaces.json"
+
+	defaultCSharpNamespace = "Provider"
+)
+
+// runGen implements the `gen` command.
+func runGen(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ContinueOnError)
+	specPath := flags.String("spec", "", "path to the OpenAPI spec (JSON or YAML)")
+	swagger := flags.Bool("swagger", false, "the spec is a Swagger 2.0 spec, which is converted to OpenAPI 3 first")
+	configPath := flags.String("config", "", "path to the conversion config (JSON or YAML)")
+	packagePath := flags.String("package", "", "path to the base Pulumi package spec (JSON or YAML), if not using -config")
+	outDir := flags.String("out", ".", "directory to write the generated files to")
+	lockPath := flags.String("lock", "", "path to the naming lock file, which keeps the resource and function tokens stable; created if it doesn't exist")
+	previousDir := flags.String("previous", "", "directory with the schema.json and metadata.json generated earlier, used to add aliases for the moved resources")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: pulschema gen -spec <file> [-swagger] (-config <file> | -package <file>) [-out <dir>] [-lock <file>] [-previous <dir>]\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *specPath == "" {
+		return errors.New("-spec is required")
+	}
+
+	var cfg *pkg.Config
+	switch {
+	case *configPath != "" && *packagePath != "":
+		return errors.New("-config and -package are mutually exclusive")
+	case *configPath != "":
+		c, err := pkg.LoadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+		cfg = c
+	case *packagePath != "":
+		pkgSpec, err := loadPackageSpec(*packagePath)
+		if err != nil {
+			return err
+		}
+		cfg = &pkg.Config{
+			Version: pkg.ConfigVersionV1,
+			Package: pkgSpec,
+		}
+	default:
+		return errors.New("one of -config or -package is required")
+	}
+
+	load := loadOpenAPIDoc
+	if *swagger {
+		load = pkg.LoadSwaggerDoc
+	}
+
+	doc, err := load(*specPath)
+	if err != nil {
+		return err
+	}
+
+	openAPICtx, err := cfg.NewOpenAPIContext(doc)
+	if err != nil {
+		return err
+	}
+	pkgSpec := openAPICtx.Pkg
+
+	if *previousDir != "" {
+		previousPkg, previousMetadata, err := loadGeneratedFiles(*previousDir)
+		if err != nil {
+			return err
+		}
+		openAPICtx.PreviousPackage = previousPkg
+		openAPICtx.PreviousMetadata = previousMetadata
+	}
+
+	if *lockPath != "" {
+		if _, err := os.Stat(*lockPath); err == nil {
+			lock, err := pkg.LoadNamingLock(*lockPath)
+			if err != nil {
+				return err
+			}
+			openAPICtx.NamingLock = lock
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return errors.Wrapf(err, "reading naming lock %s", *lockPath)
+		}
+	}
+
+	csharpNamespaces := map[string]string{
+		"": defaultCSharpNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	for _, diag := range openAPICtx.Diagnostics() {
+		if diag.Severity == pkg.SeverityWarning {
+			fmt.Fprintln(os.Stderr, diag.String())
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, "generating the pulumi schema")
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		return errors.Wrapf(err, "creating output directory %s", *outDir)
+	}
+
+	outputs := map[string]interface{}{
+		schemaFileName:           pkgSpec,
+		metadataFileName:         metadata,
+		csharpNamespacesFileName: csharpNamespaces,
+	}
+	for fileName, v := range outputs {
+		if err := writeJSONFile(filepath.Join(*outDir, fileName), v); err != nil {
+			return err
+		}
+	}
+
+	if *lockPath != "" {
+		if err := writeJSONFile(*lockPath, openAPICtx.GeneratedNamingLock()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadOpenAPIDoc loads and validates the OpenAPI spec at path.
+func loadOpenAPIDoc(path string) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading openapi spec %s", path)
+	}
+
+	if err := doc.Validate(context.Background(), openapi3.DisableExamplesValidation()); err != nil {
+		return nil, errors.Wrapf(err, "validating openapi spec %s", path)
+	}
+
+	return doc, nil
+}
+
+// loadPackageSpec reads the base Pulumi package spec at path. The
+// resources, functions and types are populated by the conversion,
+// so they are usually left empty in the file.
+//
+// Prefer the `-config` flag, which also carries the conversion options.
+func loadPackageSpec(path string) (*pschema.PackageSpec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading package spec %s", path)
+	}
+
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		b, err = yaml.YAMLToJSON(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "converting package spec %s to json", path)
+		}
+	}
+
+	var pkgSpec pschema.PackageSpec
+	if err := json.Unmarshal(b, &pkgSpec); err != nil {
+		return nil, errors.Wrapf(err, "parsing package spec %s", path)
+	}
+
+	return &pkgSpec, nil
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return errors.Wrapf(err, "marshaling %s", filepath.Base(path))
+	}
+
+	if err := os.WriteFile(path, append(b, '\n'), 0o600); err != nil {
+		return errors.Wrapf(err, "writing %s", path)
+	}
+
+	return nil
+}
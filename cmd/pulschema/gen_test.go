@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/cloudy-sky-software/pulschema/pkg"
+)
+
+func TestGen(t *testing.T) {
+	outDir := t.TempDir()
+
+	err := runGen([]string{
+		"-spec", filepath.Join("..", "..", "pkg", "testdata", "simple_property_ref_openapi.yml"),
+		"-package", filepath.Join("testdata", "package.yaml"),
+		"-out", outDir,
+	})
+	assert.Nil(t, err)
+
+	b, err := os.ReadFile(filepath.Join(outDir, schemaFileName))
+	assert.Nil(t, err)
+	var pkgSpec pschema.PackageSpec
+	assert.Nil(t, json.Unmarshal(b, &pkgSpec))
+	assert.Equal(t, "fake-package", pkgSpec.Name)
+	assert.Contains(t, pkgSpec.Resources, "fake-package:fakeresource/v2:FakeResource")
+	assert.True(t, pkgSpec.Provider.InputProperties["apiKey"].Secret)
+
+	b, err = os.ReadFile(filepath.Join(outDir, metadataFileName))
+	assert.Nil(t, err)
+	var metadata pkg.ProviderMetadata
+	assert.Nil(t, json.Unmarshal(b, &metadata))
+	assert.Contains(t, metadata.ResourceCRUDMap, "fake-package:fakeresource/v2:FakeResource")
+
+	b, err = os.ReadFile(filepath.Join(outDir, csharpNamespacesFileName))
+	assert.Nil(t, err)
+	var csharpNamespaces map[string]string
+	assert.Nil(t, json.Unmarshal(b, &csharpNamespaces))
+	assert.Equal(t, "Provider", csharpNamespaces[""])
+	assert.Equal(t, "FakeresourceV2", csharpNamespaces["fakeresource/v2"])
+}
+
+func TestGenWithConfig(t *testing.T) {
+	outDir := t.TempDir()
+
+	err := runGen([]string{
+		"-spec", filepath.Join("..", "..", "pkg", "testdata", "simple_property_ref_openapi.yml"),
+		"-config", filepath.Join("testdata", "config.yaml"),
+		"-out", outDir,
+	})
+	assert.Nil(t, err)
+
+	b, err := os.ReadFile(filepath.Join(outDir, schemaFileName))
+	assert.Nil(t, err)
+	var pkgSpec pschema.PackageSpec
+	assert.Nil(t, json.Unmarshal(b, &pkgSpec))
+	assert.Contains(t, pkgSpec.Resources, "fake-package:fakeresource/v2:FakeResource")
+	// The config excludes the only GET endpoint.
+	assert.NotContains(t, pkgSpec.Functions, "fake-package:simpleresource/v2:getSimpleResource")
+}
+
+func TestGenConfigAndPackageAreMutuallyExclusive(t *testing.T) {
+	err := runGen([]string{
+		"-spec", filepath.Join("..", "..", "pkg", "testdata", "simple_property_ref_openapi.yml"),
+		"-config", filepath.Join("testdata", "config.yaml"),
+		"-package", filepath.Join("testdata", "package.yaml"),
+	})
+	assert.EqualError(t, err, "-config and -package are mutually exclusive")
+}
+
+func TestGenRequiresSpec(t *testing.T) {
+	err := runGen([]string{"-package", filepath.Join("testdata", "package.yaml")})
+	assert.EqualError(t, err, "-spec is required")
+}
+
+func TestGenSwagger(t *testing.T) {
+	outDir := t.TempDir()
+
+	err := runGen([]string{
+		"-spec", filepath.Join("..", "..", "pkg", "testdata", "swagger_openapi.yml"),
+		"-swagger",
+		"-package", filepath.Join("testdata", "package.yaml"),
+		"-out", outDir,
+	})
+	assert.Nil(t, err)
+
+	b, err := os.ReadFile(filepath.Join(outDir, schemaFileName))
+	assert.Nil(t, err)
+	var pkgSpec pschema.PackageSpec
+	assert.Nil(t, json.Unmarshal(b, &pkgSpec))
+	assert.Contains(t, pkgSpec.Resources, "fake-package:toasters/v2:Toaster")
+}
+
+func TestGenNamingLock(t *testing.T) {
+	outDir := t.TempDir()
+	lockPath := filepath.Join(t.TempDir(), "pulschema.lock.json")
+
+	gen := func() pschema.PackageSpec {
+		err := runGen([]string{
+			"-spec", filepath.Join("..", "..", "pkg", "testdata", "naming_lock_openapi.yml"),
+			"-package", filepath.Join("testdata", "package.yaml"),
+			"-out", outDir,
+			"-lock", lockPath,
+		})
+		assert.Nil(t, err)
+
+		b, err := os.ReadFile(filepath.Join(outDir, schemaFileName))
+		assert.Nil(t, err)
+		var pkgSpec pschema.PackageSpec
+		assert.Nil(t, json.Unmarshal(b, &pkgSpec))
+		return pkgSpec
+	}
+
+	// The first run creates the lock file.
+	pkgSpec := gen()
+	assert.Contains(t, pkgSpec.Resources, "fake-package:beacons/v2:Signal")
+	lock, err := pkg.LoadNamingLock(lockPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "fake-package:beacons/v2:Signal", lock.Resources["POST /v2/beacons"])
+
+	// Simulate a token that was generated by an earlier version.
+	lock.Resources["POST /v2/beacons"] = "fake-package:beacons/v2:Beacon"
+	b, err := json.Marshal(lock)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(lockPath, b, 0o600))
+
+	pkgSpec = gen()
+	assert.Contains(t, pkgSpec.Resources, "fake-package:beacons/v2:Beacon")
+	assert.NotContains(t, pkgSpec.Resources, "fake-package:beacons/v2:Signal")
+}
+
+func TestGenPrevious(t *testing.T) {
+	previousDir := t.TempDir()
+	specPath := filepath.Join("..", "..", "pkg", "testdata", "aliases_openapi.yml")
+
+	err := runGen([]string{"-spec", specPath, "-package", filepath.Join("testdata", "package.yaml"), "-out", previousDir})
+	assert.Nil(t, err)
+
+	// Regenerate into the same directory with resources in other modules.
+	err = runGen([]string{"-spec", specPath, "-config", filepath.Join("testdata", "config_parent_module.yaml"), "-out", previousDir, "-previous", previousDir})
+	assert.Nil(t, err)
+
+	b, err := os.ReadFile(filepath.Join(previousDir, schemaFileName))
+	assert.Nil(t, err)
+	var pkgSpec pschema.PackageSpec
+	assert.Nil(t, json.Unmarshal(b, &pkgSpec))
+	assert.Equal(t, []pschema.AliasSpec{{Type: "fake-package:harbors/v2:Barge"}}, pkgSpec.Resources["fake-package:barges:Barge"].Aliases)
+}
@@ -0,0 +1,55 @@
+// Copyright 2022, Cloudy Sky Software.
+
+// Command pulschema generates a Pulumi package schema and the provider
+// metadata from an OpenAPI spec.
+//
+// Usage:
+//
+//	pulschema gen -spec openapi.yml -package package.yaml -out ./provider
+//	pulschema diff -old ./provider-v1 -new ./provider
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `pulschema generates a Pulumi package schema from an OpenAPI spec.
+
+Usage:
+
+	pulschema <command> [flags]
+
+Commands:
+
+	gen     generate schema.json, metadata.json and csharp-namespaces.json
+	diff    compare two generated schemas and report the breaking changes
+
+Run "pulschema <command> -h" for more information about a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd := os.Args[1]; cmd {
+	case "gen":
+		err = runGen(os.Args[2:])
+	case "diff":
+		err = runDiff(os.Args[2:], os.Stdout)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// gatherResourceAliases adds the earlier tokens of the resources to their
+// aliases so that Pulumi moves the existing resources to the new tokens
+// instead of replacing them.
+//
+// The earlier tokens come from the TokenHistory and from the resources of
+// the PreviousPackage that are no longer generated. A previous resource is
+// matched with the resource that has the same create endpoint in the
+// PreviousMetadata, if any, or else with the only new resource that has the
+// same name in another module, which is what happens when a resource moves
+// between modules. The aliases of the previous resource are carried over.
+func (o *OpenAPIContext) gatherResourceAliases() {
+	aliases := make(map[string]codegen.StringSet)
+	addAliases := func(tok string, oldToks ...string) {
+		if _, ok := aliases[tok]; !ok {
+			aliases[tok] = codegen.NewStringSet()
+		}
+		for _, oldTok := range oldToks {
+			if oldTok != tok {
+				aliases[tok].Add(oldTok)
+			}
+		}
+	}
+
+	for _, tok := range slices.Sorted(maps.Keys(o.TokenHistory)) {
+		if _, ok := o.Pkg.Resources[tok]; !ok {
+			o.diagnostics.warnf(CodeUnknownResourceToken, "", tok, "the token history is for a resource that wasn't generated")
+			continue
+		}
+		addAliases(tok, o.TokenHistory[tok]...)
+	}
+
+	for oldTok, tok := range o.getMovedResources() {
+		addAliases(tok, oldTok)
+		for _, alias := range o.PreviousPackage.Resources[oldTok].Aliases {
+			addAliases(tok, alias.Type)
+		}
+	}
+
+	for _, tok := range slices.Sorted(maps.Keys(aliases)) {
+		resourceSpec := o.Pkg.Resources[tok]
+		existing := codegen.NewStringSet()
+		for _, alias := range resourceSpec.Aliases {
+			existing.Add(alias.Type)
+		}
+
+		for _, oldTok := range aliases[tok].SortedValues() {
+			if !existing.Has(oldTok) {
+				resourceSpec.Aliases = append(resourceSpec.Aliases, pschema.AliasSpec{Type: oldTok})
+			}
+		}
+		o.Pkg.Resources[tok] = resourceSpec
+	}
+}
+
+// getMovedResources returns a map of the tokens of the resources of the
+// PreviousPackage that are no longer generated to their new tokens.
+func (o *OpenAPIContext) getMovedResources() map[string]string {
+	moved := make(map[string]string)
+	if o.PreviousPackage == nil {
+		return moved
+	}
+
+	var removed, added []string
+	for _, tok := range slices.Sorted(maps.Keys(o.PreviousPackage.Resources)) {
+		if _, ok := o.Pkg.Resources[tok]; !ok {
+			removed = append(removed, tok)
+		}
+	}
+	for _, tok := range slices.Sorted(maps.Keys(o.Pkg.Resources)) {
+		if _, ok := o.PreviousPackage.Resources[tok]; !ok {
+			added = append(added, tok)
+		}
+	}
+
+	matched := codegen.NewStringSet()
+	match := func(oldKey, newKey func(string) string) {
+		for _, oldTok := range removed {
+			k := oldKey(oldTok)
+			if k == "" || moved[oldTok] != "" {
+				continue
+			}
+
+			var candidates []string
+			for _, tok := range added {
+				if !matched.Has(tok) && newKey(tok) == k {
+					candidates = append(candidates, tok)
+				}
+			}
+			if len(candidates) == 1 {
+				moved[oldTok] = candidates[0]
+				matched.Add(candidates[0])
+			}
+		}
+	}
+
+	if o.PreviousMetadata != nil {
+		match(createEndpointKey(o.PreviousMetadata), createEndpointKey(&ProviderMetadata{ResourceCRUDMap: o.resourceCRUDMap}))
+	}
+	match(getTokenName, getTokenName)
+
+	return moved
+}
+
+// getTokenName returns the name part of a type token.
+func getTokenName(tok string) string {
+	return tok[strings.LastIndex(tok, ":")+1:]
+}
@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// newAliasesTestContext returns an OpenAPIContext for the aliases test
+// doc that converts it into a new package.
+func newAliasesTestContext(t *testing.T) *OpenAPIContext {
+	doc, err := openapi3.NewLoader().LoadFromFile(filepath.Join("testdata", "aliases_openapi.yml"))
+	require.NoError(t, err)
+
+	return &OpenAPIContext{
+		Doc: *doc,
+		Pkg: &pschema.PackageSpec{
+			Name:      packageName,
+			Types:     map[string]pschema.ComplexTypeSpec{},
+			Resources: map[string]pschema.ResourceSpec{},
+			Functions: map[string]pschema.FunctionSpec{},
+			Language:  map[string]pschema.RawMessage{},
+		},
+	}
+}
+
+func getAliasTypes(resourceSpec pschema.ResourceSpec) []string {
+	var types []string
+	for _, alias := range resourceSpec.Aliases {
+		types = append(types, alias.Type)
+	}
+	return types
+}
+
+func TestTokenHistory(t *testing.T) {
+	openAPICtx := newAliasesTestContext(t)
+	openAPICtx.TokenHistory = map[string][]string{
+		packageName + ":harbors/v2:Harbor": {packageName + ":harbors:Harbor", packageName + ":ports:Harbor"},
+		packageName + ":harbors/v2:Dock":   {packageName + ":harbors:Dock"},
+	}
+
+	_, _, err := openAPICtx.GatherResourcesFromAPI(map[string]string{"": providerNamespace})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{packageName + ":harbors:Harbor", packageName + ":ports:Harbor"}, getAliasTypes(openAPICtx.Pkg.Resources[packageName+":harbors/v2:Harbor"]))
+	assert.Empty(t, openAPICtx.Pkg.Resources[packageName+":harbors/v2:Barge"].Aliases)
+
+	diags := openAPICtx.Diagnostics()
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, CodeUnknownResourceToken, diags[0].Code)
+		assert.Equal(t, packageName+":harbors/v2:Dock", diags[0].Token)
+	}
+}
+
+// Test to ensure that flipping UseParentResourceAsModule adds the
+// tokens from the previous package to the aliases of the resources.
+func TestAliasesFromPreviousPackage(t *testing.T) {
+	previousCtx := newAliasesTestContext(t)
+	previousMetadata, _, err := previousCtx.GatherResourcesFromAPI(map[string]string{"": providerNamespace})
+	require.NoError(t, err)
+
+	bargeTok := packageName + ":harbors/v2:Barge"
+	require.Contains(t, previousCtx.Pkg.Resources, bargeTok)
+	// The aliases of the previous resources are carried over.
+	previousBarge := previousCtx.Pkg.Resources[bargeTok]
+	previousBarge.Aliases = []pschema.AliasSpec{{Type: packageName + ":boats:Barge"}}
+	previousCtx.Pkg.Resources[bargeTok] = previousBarge
+
+	for name, metadata := range map[string]*ProviderMetadata{"with metadata": previousMetadata, "without metadata": nil} {
+		t.Run(name, func(t *testing.T) {
+			openAPICtx := newAliasesTestContext(t)
+			openAPICtx.UseParentResourceAsModule = true
+			openAPICtx.PreviousPackage = previousCtx.Pkg
+			openAPICtx.PreviousMetadata = metadata
+
+			_, _, err := openAPICtx.GatherResourcesFromAPI(map[string]string{"": providerNamespace})
+			assert.Nil(t, err)
+
+			assert.Equal(t, []string{packageName + ":harbors/v2:Harbor"}, getAliasTypes(openAPICtx.Pkg.Resources[packageName+":harbors:Harbor"]))
+			assert.Equal(t, []string{packageName + ":boats:Barge", bargeTok}, getAliasTypes(openAPICtx.Pkg.Resources[packageName+":barges:Barge"]))
+		})
+	}
+}
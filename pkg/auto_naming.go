@@ -0,0 +1,114 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// defaultAutoNameProperties are the properties that can be auto-named
+// when AutoNameProperties is not set.
+var defaultAutoNameProperties = []string{propertyName}
+
+// charsetPatternRegex matches the patterns that only allow the characters
+// of a single character class, e.g. `^[a-z0-9-]+$` or `^[a-z0-9-]{1,63}$`.
+var charsetPatternRegex = regexp.MustCompile(`^\^\[([^\]\^][^\]]*)\](?:[*+]|\{\d+(?:,\d*)?\})\$$`)
+
+// getAutoNameProperty returns the API name of the property of a resource's
+// request body schema that the provider can auto-name, or an empty string
+// if there is none.
+//
+// A property with the ExtAutoName extension is auto-named even if it's not
+// required. Otherwise, the first required property listed in
+// AutoNameProperties is auto-named, unless the extension is set to false
+// on it.
+func (o *OpenAPIContext) getAutoNameProperty(pointer, typeToken string, requestBodySchema openapi3.Schema) string {
+	canAutoName := func(prop *openapi3.SchemaRef) bool {
+		return prop != nil && prop.Value != nil && !prop.Value.ReadOnly && !isNullUnion(prop.Value)
+	}
+
+	optedOut := make(map[string]bool)
+	var autoNameProp string
+	for _, propName := range slices.Sorted(maps.Keys(requestBodySchema.Properties)) {
+		prop := requestBodySchema.Properties[propName]
+		if prop.Value == nil {
+			continue
+		}
+		v, ok := prop.Value.Extensions[ExtAutoName]
+		if !ok {
+			continue
+		}
+
+		switch ext := v.(type) {
+		case bool:
+			if !ext {
+				optedOut[propName] = true
+				continue
+			}
+		case map[string]any:
+		default:
+			o.diagnostics.warnf(CodeInvalidExtension, pointer, typeToken, "%s on property %s must be a boolean or an object but got %v", ExtAutoName, propName, v)
+			continue
+		}
+
+		switch {
+		case !canAutoName(prop):
+			o.diagnostics.warnf(CodeInvalidExtension, pointer, typeToken, "%s is ignored on property %s because it's read-only or nullable", ExtAutoName, propName)
+		case autoNameProp != "":
+			o.diagnostics.warnf(CodeInvalidExtension, pointer, typeToken, "%s is set on properties %s and %s; only %s is auto-named", ExtAutoName, autoNameProp, propName, autoNameProp)
+		default:
+			autoNameProp = propName
+		}
+	}
+	if autoNameProp != "" {
+		return autoNameProp
+	}
+
+	candidates := o.AutoNameProperties
+	if len(candidates) == 0 {
+		candidates = defaultAutoNameProperties
+	}
+	for _, candidate := range candidates {
+		if optedOut[candidate] || !slices.Contains(requestBodySchema.Required, candidate) {
+			continue
+		}
+		if canAutoName(requestBodySchema.Properties[candidate]) {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// getAutoNameConstraints returns the constraints on the values of an
+// auto-named property, or nil if it doesn't have any. The charset comes
+// from the `charset` of the ExtAutoName extension, if any, or else from
+// a pattern that only allows the characters of a single character class.
+func getAutoNameConstraints(s *openapi3.Schema) *AutoNameConstraints {
+	c := &AutoNameConstraints{
+		MaxLength: s.MaxLength,
+		Pattern:   s.Pattern,
+	}
+	if s.MinLength > 0 {
+		c.MinLength = &s.MinLength
+	}
+
+	if ext, ok := s.Extensions[ExtAutoName].(map[string]any); ok {
+		c.Charset, _ = ext["charset"].(string)
+	}
+	if c.Charset == "" {
+		if m := charsetPatternRegex.FindStringSubmatch(s.Pattern); m != nil {
+			c.Charset = m[1]
+		}
+	}
+
+	if *c == (AutoNameConstraints{}) {
+		return nil
+	}
+
+	return c
+}
@@ -0,0 +1,73 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAutoNaming tests that the auto-named property can be picked using
+// AutoNameProperties and the ExtAutoName extension, and that its
+// constraints are listed in the metadata.
+func TestAutoNaming(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "auto_naming_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc:                *testOpenAPIDoc,
+		Pkg:                &testPulumiPkg,
+		AutoNameProperties: []string{"name", "display_name"},
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(map[string]string{"": providerNamespace})
+	require.NoError(t, err)
+
+	kayak := packageName + ":kayaks/v2:Kayak"
+	oar := packageName + ":oars/v2:Oar"
+	tarp := packageName + ":tarps/v2:Tarp"
+
+	assert.Equal(t, "displayName", metadata.AutoNameMap[kayak])
+	assert.Equal(t, "slug", metadata.AutoNameMap[oar])
+	assert.Equal(t, "name", metadata.AutoNameMap[tarp])
+
+	assert.NotContains(t, testPulumiPkg.Resources[kayak].RequiredInputs, "displayName")
+	assert.Contains(t, testPulumiPkg.Resources[oar].RequiredInputs, "name")
+	assert.NotContains(t, testPulumiPkg.Resources[tarp].RequiredInputs, "name")
+
+	uint64Ptr := func(v uint64) *uint64 { return &v }
+	assert.Equal(t, &AutoNameConstraints{MaxLength: uint64Ptr(63), Pattern: "^[a-z0-9-]+$", Charset: "a-z0-9-"}, metadata.AutoNameConstraintsMap[kayak])
+	assert.Equal(t, &AutoNameConstraints{MinLength: uint64Ptr(3), Charset: "a-z"}, metadata.AutoNameConstraintsMap[oar])
+	assert.NotContains(t, metadata.AutoNameConstraintsMap, tarp)
+}
+
+// TestAutoNamingDefaultProperties tests that only `name` is auto-named
+// when AutoNameProperties is not set.
+func TestAutoNamingDefaultProperties(t *testing.T) {
+	pkg, metadata := generateTestPackage(t, filepath.Join("testdata", "auto_naming_openapi.yml"))
+
+	kayak := packageName + ":kayaks/v2:Kayak"
+	assert.NotContains(t, metadata.AutoNameMap, kayak)
+	assert.Contains(t, pkg.Resources[kayak].RequiredInputs, "displayName")
+	assert.Equal(t, "name", metadata.AutoNameMap[packageName+":tarps/v2:Tarp"])
+}
+
+func TestGetAutoNameConstraintsCharset(t *testing.T) {
+	tests := map[string]string{
+		"^[a-z0-9-]+$":      "a-z0-9-",
+		"^[a-z0-9-]*$":      "a-z0-9-",
+		"^[a-z0-9-]{1,63}$": "a-z0-9-",
+		"^[a-z][a-z0-9-]*$": "",
+		"^[^/]+$":           "",
+		"[a-z]+":            "",
+	}
+
+	for pattern, charset := range tests {
+		t.Run(pattern, func(t *testing.T) {
+			c := getAutoNameConstraints(&openapi3.Schema{Pattern: pattern})
+			require.NotNil(t, c)
+			assert.Equal(t, charset, c.Charset)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+const (
+	assetTypeRef   = "pulumi.json#/Asset"
+	archiveTypeRef = "pulumi.json#/Archive"
+
+	// rawBodyPropertyName is the name of the input property of a
+	// resource whose create request body is just a file.
+	rawBodyPropertyName = "content"
+)
+
+// archiveMimeTypes are the media types of files that are
+// mapped to archives instead of assets.
+var archiveMimeTypes = codegen.NewStringSet(
+	"application/zip",
+	"application/x-zip-compressed",
+	"application/x-tar",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-gtar",
+)
+
+// isBinarySchema returns true if the schema is for the contents of a
+// file, i.e. `format: binary` or, in OpenAPI 3.1, `contentMediaType`.
+func isBinarySchema(s *openapi3.Schema) bool {
+	if s == nil || !(s.Type.IsEmpty() || s.Type.Includes(openapi3.TypeString)) {
+		return false
+	}
+
+	return s.Format == "binary" || s.ContentMediaType != ""
+}
+
+// getBinaryTypeSpec returns the asset or archive type spec for a binary
+// schema, or nil if the schema isn't binary.
+func getBinaryTypeSpec(s *openapi3.Schema) *pschema.TypeSpec {
+	if !isBinarySchema(s) {
+		return nil
+	}
+
+	if archiveMimeTypes.Has(s.ContentMediaType) {
+		return &pschema.TypeSpec{Ref: archiveTypeRef}
+	}
+
+	return &pschema.TypeSpec{Ref: assetTypeRef}
+}
+
+// newRawBodyMediaType returns a media type for an object with a single
+// rawBodyPropertyName property for the file that is the request body.
+func newRawBodyMediaType(fileSchema *openapi3.SchemaRef) *openapi3.MediaType {
+	return openapi3.NewMediaType().WithSchema(&openapi3.Schema{
+		Type:       &openapi3.Types{openapi3.TypeObject},
+		Properties: openapi3.Schemas{rawBodyPropertyName: fileSchema},
+		Required:   []string{rawBodyPropertyName},
+	})
+}
+
+// gatherBinaryProperties records how the asset and archive inputs of the
+// resources gathered from the API are sent in their create request body.
+//
+// A file that is the whole request body is sent raw. Top-level files of
+// a multipart/form-data body are sent as parts, with the content type
+// from the `encoding` of the media type, if any. All other files are
+// sent as base64 encoded strings.
+func (o *OpenAPIContext) gatherBinaryProperties() {
+	for _, tok := range slices.Sorted(maps.Keys(o.resourceCRUDMap)) {
+		desc := o.resourceCRUDMap[tok].Operations.C
+		resourceSpec, ok := o.Pkg.Resources[tok]
+		if !ok || desc == nil || desc.RequestContentType == "" {
+			continue
+		}
+
+		op := o.Doc.Paths.Find(desc.Path).GetOperation(desc.Method)
+		mediaType := op.RequestBody.Value.Content.Get(desc.RequestContentType)
+		if mediaType == nil || mediaType.Schema == nil || mediaType.Schema.Value == nil {
+			continue
+		}
+
+		encodings := make(map[string]*BinaryEncoding)
+		if isBinarySchema(mediaType.Schema.Value) {
+			encodings[rawBodyPropertyName] = &BinaryEncoding{
+				Encoding:    BinaryEncodingTypeRaw,
+				ContentType: desc.RequestContentType,
+			}
+		} else {
+			props := getAPISchemaProperties(mediaType.Schema.Value)
+			for _, name := range slices.Sorted(maps.Keys(props)) {
+				sdkName := ToSdkName(name)
+				prop := props[name].Value
+				if _, ok := resourceSpec.InputProperties[sdkName]; !ok || prop == nil {
+					continue
+				}
+
+				if desc.RequestContentType != multipartFormDataMimeType {
+					maps.Copy(encodings, getBinaryEncodings(sdkName, prop, map[*openapi3.Schema]bool{}))
+					continue
+				}
+
+				// Each item of an array of files is a separate part
+				// with the same name.
+				path, fileSchema := sdkName, prop
+				if prop.Items != nil && isBinarySchema(prop.Items.Value) {
+					path, fileSchema = sdkName+"[*]", prop.Items.Value
+				}
+				if !isBinarySchema(fileSchema) {
+					// Object parts are JSON, so files in them
+					// are base64 encoded.
+					maps.Copy(encodings, getBinaryEncodings(sdkName, prop, map[*openapi3.Schema]bool{}))
+					continue
+				}
+
+				encodings[path] = &BinaryEncoding{
+					Encoding:    BinaryEncodingTypeMultipart,
+					ContentType: getPartContentType(mediaType, name, fileSchema),
+				}
+			}
+		}
+
+		// Files in the types of component schemas are strings,
+		// so only the inputs that are assets or archives are
+		// recorded.
+		maps.DeleteFunc(encodings, func(path string, _ *BinaryEncoding) bool {
+			return !o.isBinaryInput(resourceSpec.InputProperties, path)
+		})
+
+		if len(encodings) > 0 {
+			o.binaryPropertiesMap[tok] = encodings
+		}
+	}
+}
+
+// isBinaryInput returns true if the input at path, as recorded in the
+// binaryPropertiesMap, is an asset or an archive.
+func (o *OpenAPIContext) isBinaryInput(inputs map[string]pschema.PropertySpec, path string) bool {
+	var typeSpec *pschema.TypeSpec
+	for _, name := range strings.Split(path, ".") {
+		if typeSpec != nil {
+			objectType, ok := o.Pkg.Types[strings.TrimPrefix(typeSpec.Ref, typesSchemaRefPrefix)]
+			if !ok || !strings.HasPrefix(typeSpec.Ref, typesSchemaRefPrefix) {
+				return false
+			}
+			inputs = objectType.Properties
+		}
+
+		prop, ok := inputs[strings.ReplaceAll(name, "[*]", "")]
+		if !ok {
+			return false
+		}
+
+		typeSpec = &prop.TypeSpec
+		for range strings.Count(name, "[*]") {
+			typeSpec = typeSpec.Items
+			if typeSpec == nil {
+				return false
+			}
+		}
+	}
+
+	return typeSpec != nil && (typeSpec.Ref == assetTypeRef || typeSpec.Ref == archiveTypeRef)
+}
+
+// getBinaryEncodings returns the base64 encodings of the schema at path,
+// if it is binary, or of its nested binary properties, using their SDK
+// names. Array items are denoted with `[*]` in the paths.
+func getBinaryEncodings(path string, schema *openapi3.Schema, visited map[*openapi3.Schema]bool) map[string]*BinaryEncoding {
+	if schema == nil || visited[schema] {
+		return nil
+	}
+
+	if isBinarySchema(schema) {
+		return map[string]*BinaryEncoding{
+			path: {Encoding: BinaryEncodingTypeBase64, ContentType: schema.ContentMediaType},
+		}
+	}
+
+	visited[schema] = true
+	defer delete(visited, schema)
+
+	if schema.Items != nil {
+		return getBinaryEncodings(path+"[*]", schema.Items.Value, visited)
+	}
+
+	encodings := make(map[string]*BinaryEncoding)
+	for name, prop := range getAPISchemaProperties(schema) {
+		maps.Copy(encodings, getBinaryEncodings(path+"."+ToSdkName(name), prop.Value, visited))
+	}
+
+	return encodings
+}
+
+// getPartContentType returns the content type of the file part of a
+// multipart/form-data body, which defaults to `application/octet-stream`.
+func getPartContentType(mediaType *openapi3.MediaType, partName string, fileSchema *openapi3.Schema) string {
+	if encoding, ok := mediaType.Encoding[partName]; ok && encoding != nil && encoding.ContentType != "" {
+		return encoding.ContentType
+	}
+
+	if fileSchema.ContentMediaType != "" {
+		return fileSchema.ContentMediaType
+	}
+
+	return octetStreamMimeType
+}
@@ -0,0 +1,103 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBinaryProperties tests that files are mapped to assets and
+// archives, and that the metadata says how to send them.
+func TestBinaryProperties(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "binary_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	t.Run("Multipart", func(t *testing.T) {
+		resourceToken := "fake-package:photos/v2:Photo"
+		resourceSpec, ok := testPulumiPkg.Resources[resourceToken]
+		assert.Truef(t, ok, "Expected to find a resource called Photo: %v", testPulumiPkg.Resources)
+
+		assert.Equal(t, assetTypeRef, resourceSpec.InputProperties["file"].Ref)
+		assert.Equal(t, assetTypeRef, resourceSpec.InputProperties["attachments"].Items.Ref)
+		assert.Contains(t, resourceSpec.RequiredInputs, "file")
+		assert.Equal(t, multipartFormDataMimeType, metadata.ResourceCRUDMap[resourceToken].Operations.C.RequestContentType)
+
+		assert.Equal(t, map[string]*BinaryEncoding{
+			"file":               {Encoding: BinaryEncodingTypeMultipart, ContentType: "image/png"},
+			"attachments[*]":     {Encoding: BinaryEncodingTypeMultipart, ContentType: octetStreamMimeType},
+			"metadata.thumbnail": {Encoding: BinaryEncodingTypeBase64},
+		}, metadata.BinaryPropertiesMap[resourceToken])
+	})
+
+	t.Run("RawBody", func(t *testing.T) {
+		resourceToken := "fake-package:backups/v2:Backup"
+		resourceSpec, ok := testPulumiPkg.Resources[resourceToken]
+		assert.Truef(t, ok, "Expected to find a resource called Backup: %v", testPulumiPkg.Resources)
+
+		assert.Equal(t, assetTypeRef, resourceSpec.InputProperties[rawBodyPropertyName].Ref)
+		assert.Contains(t, resourceSpec.RequiredInputs, rawBodyPropertyName)
+		assert.Equal(t, map[string]*BinaryEncoding{
+			rawBodyPropertyName: {Encoding: BinaryEncodingTypeRaw, ContentType: octetStreamMimeType},
+		}, metadata.BinaryPropertiesMap[resourceToken])
+	})
+
+	t.Run("Response", func(t *testing.T) {
+		funcSpec, ok := testPulumiPkg.Functions["fake-package:photos/v2:getPhoto"]
+		assert.Truef(t, ok, "Expected to find a function called getPhoto: %v", testPulumiPkg.Functions)
+
+		contentType := testPulumiPkg.Types[strings.TrimPrefix(funcSpec.ReturnType.TypeSpec.Ref, typesSchemaRefPrefix)]
+		assert.Equal(t, "string", contentType.Properties["data"].Type)
+		assert.Empty(t, contentType.Properties["data"].Ref)
+	})
+
+	t.Run("ComponentSchema", func(t *testing.T) {
+		// The types of component schemas are shared by requests and
+		// responses, so their files are strings whether the request
+		// or the response is converted first.
+		for _, name := range []string{"App", "Kit"} {
+			resourceToken := "fake-package:" + strings.ToLower(name) + "s/v2:" + name
+			resourceSpec, ok := testPulumiPkg.Resources[resourceToken]
+			if !assert.Truef(t, ok, "Expected to find a resource called %s: %v", name, testPulumiPkg.Resources) {
+				continue
+			}
+
+			bundleType := testPulumiPkg.Types[strings.TrimPrefix(resourceSpec.InputProperties["bundle"].Ref, typesSchemaRefPrefix)]
+			assert.Equal(t, "string", bundleType.Properties["file"].Type)
+			assert.Empty(t, bundleType.Properties["file"].Ref)
+			assert.NotContains(t, metadata.BinaryPropertiesMap, resourceToken)
+		}
+	})
+
+	t.Run("WildcardContentType", func(t *testing.T) {
+		resourceToken := "fake-package:labels/v2:Label"
+		resourceSpec, ok := testPulumiPkg.Resources[resourceToken]
+		assert.Truef(t, ok, "Expected to find a resource called Label: %v", testPulumiPkg.Resources)
+
+		assert.Contains(t, resourceSpec.InputProperties, "text")
+		assert.Equal(t, jsonMimeType, metadata.ResourceCRUDMap[resourceToken].Operations.C.RequestContentType)
+	})
+
+	t.Run("Archive", func(t *testing.T) {
+		resourceToken := "fake-package:bundles/v2:Bundle"
+		resourceSpec, ok := testPulumiPkg.Resources[resourceToken]
+		assert.Truef(t, ok, "Expected to find a resource called Bundle: %v", testPulumiPkg.Resources)
+
+		assert.Equal(t, archiveTypeRef, resourceSpec.InputProperties["archive"].Ref)
+		assert.Equal(t, map[string]*BinaryEncoding{
+			"archive": {Encoding: BinaryEncodingTypeBase64, ContentType: "application/zip"},
+		}, metadata.BinaryPropertiesMap[resourceToken])
+	})
+}
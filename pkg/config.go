@@ -0,0 +1,237 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/yaml"
+	"github.com/pkg/errors"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/cloudy-sky-software/pulschema/pkg/exclusions"
+)
+
+// ConfigVersionV1 is the first version of the conversion config format.
+const ConfigVersionV1 = "v1"
+
+// Config is the declarative form of the options of an OpenAPIContext.
+// It can be written as YAML or JSON so that the conversion can be tuned
+// without writing Go code.
+type Config struct {
+	// Version is the version of the config format. Must be "v1".
+	Version string `json:"version"`
+
+	// Package is the base Pulumi package spec with the package name,
+	// publisher, config variables and provider inputs. The resources,
+	// functions and types are populated by the conversion.
+	Package *pschema.PackageSpec `json:"package"`
+
+	// Exclusions corresponds to OpenAPIContext.Exclusions.
+	Exclusions []exclusions.Exclusion `json:"exclusions,omitempty"`
+	// ExcludedPaths corresponds to OpenAPIContext.ExcludedPaths.
+	// DEPRECATED: Use Exclusions.
+	ExcludedPaths []string `json:"excludedPaths,omitempty"`
+	// UseParentResourceAsModule corresponds to
+	// OpenAPIContext.UseParentResourceAsModule.
+	UseParentResourceAsModule bool `json:"useParentResourceAsModule,omitempty"`
+	// OperationIDsHaveTypeSpecNamespace corresponds to
+	// OpenAPIContext.OperationIDsHaveTypeSpecNamespace.
+	OperationIDsHaveTypeSpecNamespace bool `json:"operationIdsHaveTypeSpecNamespace,omitempty"`
+	// TypeSpecNamespaceSeparator corresponds to
+	// OpenAPIContext.TypeSpecNamespaceSeparator.
+	TypeSpecNamespaceSeparator string `json:"typeSpecNamespaceSeparator,omitempty"`
+	// AllowedPluralResources corresponds to
+	// OpenAPIContext.AllowedPluralResources.
+	AllowedPluralResources []string `json:"allowedPluralResources,omitempty"`
+	// Strict corresponds to OpenAPIContext.Strict.
+	Strict bool `json:"strict,omitempty"`
+	// HeaderParams corresponds to OpenAPIContext.HeaderParams.
+	HeaderParams map[string]HeaderParamTarget `json:"headerParams,omitempty"`
+	// Pagination corresponds to OpenAPIContext.Pagination.
+	Pagination map[string]*Pagination `json:"pagination,omitempty"`
+	// SkipDeprecatedOperations corresponds to
+	// OpenAPIContext.SkipDeprecatedOperations.
+	SkipDeprecatedOperations bool `json:"skipDeprecatedOperations,omitempty"`
+	// AutoNameProperties corresponds to OpenAPIContext.AutoNameProperties.
+	AutoNameProperties []string `json:"autoNameProperties,omitempty"`
+	// DeduplicateTypes corresponds to OpenAPIContext.DeduplicateTypes.
+	DeduplicateTypes bool `json:"deduplicateTypes,omitempty"`
+	// SharedTypesModule corresponds to OpenAPIContext.SharedTypesModule.
+	SharedTypesModule string `json:"sharedTypesModule,omitempty"`
+	// TypeCollisionStrategy corresponds to
+	// OpenAPIContext.TypeCollisionStrategy.
+	TypeCollisionStrategy TypeCollisionStrategy `json:"typeCollisionStrategy,omitempty"`
+	// TokenHistory corresponds to OpenAPIContext.TokenHistory.
+	TokenHistory map[string][]string `json:"tokenHistory,omitempty"`
+}
+
+// LoadConfig reads and validates the conversion config file at path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading config %s", path)
+	}
+
+	c, err := ParseConfig(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading config %s", path)
+	}
+
+	return c, nil
+}
+
+// ParseConfig parses and validates a YAML or JSON conversion config.
+// Unknown fields are rejected to catch typos in option names.
+func ParseConfig(b []byte) (*Config, error) {
+	jsonBytes, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing config")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.DisallowUnknownFields()
+
+	var c Config
+	if err := decoder.Decode(&c); err != nil {
+		return nil, errors.Wrap(err, "decoding config")
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Validate checks the config for problems and returns an error
+// listing all of them.
+func (c *Config) Validate() error {
+	var problems []string
+
+	switch c.Version {
+	case ConfigVersionV1:
+	case "":
+		problems = append(problems, "version is required")
+	default:
+		problems = append(problems, fmt.Sprintf("unsupported version %q (must be %s)", c.Version, ConfigVersionV1))
+	}
+
+	if c.Package == nil {
+		problems = append(problems, "package is required")
+	} else if c.Package.Name == "" {
+		problems = append(problems, "package.name is required")
+	}
+
+	if _, err := exclusions.NewExclusionEvaluator(c.Exclusions, c.ExcludedPaths); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if c.TypeSpecNamespaceSeparator != "" && !c.OperationIDsHaveTypeSpecNamespace {
+		problems = append(problems, "typeSpecNamespaceSeparator requires operationIdsHaveTypeSpecNamespace to be true")
+	}
+
+	if c.SharedTypesModule != "" && !c.DeduplicateTypes {
+		problems = append(problems, "sharedTypesModule requires deduplicateTypes to be true")
+	}
+
+	for i, r := range c.AllowedPluralResources {
+		if r == "" {
+			problems = append(problems, fmt.Sprintf("allowedPluralResources[%d] must not be empty", i))
+		}
+	}
+
+	for i, p := range c.AutoNameProperties {
+		if p == "" {
+			problems = append(problems, fmt.Sprintf("autoNameProperties[%d] must not be empty", i))
+		}
+	}
+
+	for _, header := range slices.Sorted(maps.Keys(c.HeaderParams)) {
+		switch c.HeaderParams[header] {
+		case HeaderParamTargetProvider, HeaderParamTargetInput, HeaderParamTargetIgnore:
+		default:
+			problems = append(problems, fmt.Sprintf("headerParams.%s must be one of %s, %s or %s but got %q", header, HeaderParamTargetProvider, HeaderParamTargetInput, HeaderParamTargetIgnore, c.HeaderParams[header]))
+		}
+	}
+
+	for _, operationID := range slices.Sorted(maps.Keys(c.Pagination)) {
+		if c.Pagination[operationID] == nil {
+			problems = append(problems, fmt.Sprintf("pagination.%s must not be empty", operationID))
+		} else if err := c.Pagination[operationID].validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("pagination.%s: %v", operationID, err))
+		}
+	}
+
+	switch c.TypeCollisionStrategy {
+	case "", TypeCollisionStrategyPrefix, TypeCollisionStrategyCounter, TypeCollisionStrategyError:
+	default:
+		problems = append(problems, fmt.Sprintf("typeCollisionStrategy must be one of %s, %s or %s but got %q", TypeCollisionStrategyPrefix, TypeCollisionStrategyCounter, TypeCollisionStrategyError, c.TypeCollisionStrategy))
+	}
+
+	for _, tok := range slices.Sorted(maps.Keys(c.TokenHistory)) {
+		if !isTypeToken(tok) {
+			problems = append(problems, fmt.Sprintf("tokenHistory.%s must be a token of the form pkg:module:name", tok))
+		}
+		for i, oldTok := range c.TokenHistory[tok] {
+			if !isTypeToken(oldTok) {
+				problems = append(problems, fmt.Sprintf("tokenHistory.%s[%d] must be a token of the form pkg:module:name but got %q", tok, i, oldTok))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// NewOpenAPIContext validates the config and returns an OpenAPIContext
+// for converting doc with the options from the config.
+func (c *Config) NewOpenAPIContext(doc *openapi3.T) (*OpenAPIContext, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if c.Package.Types == nil {
+		c.Package.Types = map[string]pschema.ComplexTypeSpec{}
+	}
+	if c.Package.Resources == nil {
+		c.Package.Resources = map[string]pschema.ResourceSpec{}
+	}
+	if c.Package.Functions == nil {
+		c.Package.Functions = map[string]pschema.FunctionSpec{}
+	}
+	if c.Package.Language == nil {
+		c.Package.Language = map[string]pschema.RawMessage{}
+	}
+
+	return &OpenAPIContext{
+		Doc:                               *doc,
+		Pkg:                               c.Package,
+		Exclusions:                        c.Exclusions,
+		ExcludedPaths:                     c.ExcludedPaths,
+		UseParentResourceAsModule:         c.UseParentResourceAsModule,
+		OperationIDsHaveTypeSpecNamespace: c.OperationIDsHaveTypeSpecNamespace,
+		TypeSpecNamespaceSeparator:        c.TypeSpecNamespaceSeparator,
+		AllowedPluralResources:            c.AllowedPluralResources,
+		Strict:                            c.Strict,
+		HeaderParams:                      c.HeaderParams,
+		Pagination:                        c.Pagination,
+		SkipDeprecatedOperations:          c.SkipDeprecatedOperations,
+		AutoNameProperties:                c.AutoNameProperties,
+		DeduplicateTypes:                  c.DeduplicateTypes,
+		SharedTypesModule:                 c.SharedTypesModule,
+		TypeCollisionStrategy:             c.TypeCollisionStrategy,
+		TokenHistory:                      c.TokenHistory,
+	}, nil
+}
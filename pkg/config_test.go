@@ -0,0 +1,140 @@
+package pkg
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cloudy-sky-software/pulschema/pkg/exclusions"
+)
+
+func TestLoadConfig(t *testing.T) {
+	c, err := LoadConfig(filepath.Join("testdata", "config.yml"))
+	assert.Nil(t, err)
+
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "simple_property_ref_openapi.yml"))
+
+	openAPICtx, err := c.NewOpenAPIContext(testOpenAPIDoc)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "fake-package", openAPICtx.Pkg.Name)
+	assert.Equal(t, "Cloudy Sky Software", openAPICtx.Pkg.Publisher)
+	assert.True(t, openAPICtx.Pkg.Config.Variables["apiKey"].Secret)
+	assert.Equal(t, []string{"FAKECLOUD_APIKEY"}, openAPICtx.Pkg.Provider.InputProperties["apiKey"].DefaultInfo.Environment)
+	assert.NotNil(t, openAPICtx.Pkg.Resources)
+	assert.NotNil(t, openAPICtx.Pkg.Functions)
+	assert.NotNil(t, openAPICtx.Pkg.Types)
+
+	assert.Equal(t, []exclusions.Exclusion{
+		{Method: http.MethodGet, PathPattern: "/v2/simpleResource", PatternType: exclusions.PatternTypeExact},
+	}, openAPICtx.Exclusions)
+	assert.Equal(t, []string{"/health"}, openAPICtx.ExcludedPaths)
+	assert.True(t, openAPICtx.UseParentResourceAsModule)
+	assert.True(t, openAPICtx.OperationIDsHaveTypeSpecNamespace)
+	assert.Equal(t, "_", openAPICtx.TypeSpecNamespaceSeparator)
+	assert.Equal(t, []string{"Settings"}, openAPICtx.AllowedPluralResources)
+	assert.True(t, openAPICtx.Strict)
+	assert.Equal(t, map[string]HeaderParamTarget{"X-Project": HeaderParamTargetInput}, openAPICtx.HeaderParams)
+	assert.Equal(t, map[string]*Pagination{
+		"list_things": {Scheme: PaginationSchemeCursor, ItemsProperty: "things", CursorProperty: "meta.next", CursorParam: "from"},
+	}, openAPICtx.Pagination)
+	assert.True(t, openAPICtx.SkipDeprecatedOperations)
+	assert.Equal(t, []string{"name", "display_name"}, openAPICtx.AutoNameProperties)
+	assert.True(t, openAPICtx.DeduplicateTypes)
+	assert.Equal(t, "common", openAPICtx.SharedTypesModule)
+	assert.Equal(t, TypeCollisionStrategyCounter, openAPICtx.TypeCollisionStrategy)
+	assert.Equal(t, map[string][]string{
+		"fake-package:things:Thing": {"fake-package:things/v2:Thing"},
+	}, openAPICtx.TokenHistory)
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:   "json",
+			config: `{"version": "v1", "package": {"name": "fake-package"}}`,
+		},
+		{
+			name:    "missing version",
+			config:  "package:\n  name: fake-package\n",
+			wantErr: "invalid config: version is required",
+		},
+		{
+			name:    "unsupported version",
+			config:  "version: v2\npackage:\n  name: fake-package\n",
+			wantErr: `invalid config: unsupported version "v2" (must be v1)`,
+		},
+		{
+			name:    "missing package name",
+			config:  "version: v1\npackage:\n  publisher: Cloudy Sky Software\n",
+			wantErr: "invalid config: package.name is required",
+		},
+		{
+			name:    "unknown field",
+			config:  "version: v1\npackage:\n  name: fake-package\nuseParentResourcesAsModule: true\n",
+			wantErr: `decoding config: json: unknown field "useParentResourcesAsModule"`,
+		},
+		{
+			name:    "invalid exclusion",
+			config:  "version: v1\npackage:\n  name: fake-package\nexclusions:\n  - method: FETCH\n    pathPattern: /v2/things\n",
+			wantErr: "invalid config: invalid exclusion at index 0: invalid HTTP method: FETCH",
+		},
+		{
+			name:    "separator without typespec namespace",
+			config:  "version: v1\npackage:\n  name: fake-package\ntypeSpecNamespaceSeparator: _\n",
+			wantErr: "invalid config: typeSpecNamespaceSeparator requires operationIdsHaveTypeSpecNamespace to be true",
+		},
+		{
+			name:    "invalid header param target",
+			config:  "version: v1\npackage:\n  name: fake-package\nheaderParams:\n  X-Tenant-Id: config\n",
+			wantErr: `invalid config: headerParams.X-Tenant-Id must be one of provider, input or ignore but got "config"`,
+		},
+		{
+			name:    "invalid pagination",
+			config:  "version: v1\npackage:\n  name: fake-package\npagination:\n  list_things:\n    scheme: cursor\n    cursorParam: from\n",
+			wantErr: "invalid config: pagination.list_things: cursor pagination requires cursorProperty and cursorParam",
+		},
+		{
+			name:    "shared types module without deduplication",
+			config:  "version: v1\npackage:\n  name: fake-package\nsharedTypesModule: common\n",
+			wantErr: "invalid config: sharedTypesModule requires deduplicateTypes to be true",
+		},
+		{
+			name:    "empty auto-name property",
+			config:  "version: v1\npackage:\n  name: fake-package\nautoNameProperties:\n  - name\n  - \"\"\n",
+			wantErr: "invalid config: autoNameProperties[1] must not be empty",
+		},
+		{
+			name:    "invalid type collision strategy",
+			config:  "version: v1\npackage:\n  name: fake-package\ntypeCollisionStrategy: overwrite\n",
+			wantErr: `invalid config: typeCollisionStrategy must be one of prefix, counter or error but got "overwrite"`,
+		},
+		{
+			name:    "invalid token history",
+			config:  "version: v1\npackage:\n  name: fake-package\ntokenHistory:\n  fake-package:things:Thing:\n    - Thing\n",
+			wantErr: `invalid config: tokenHistory.fake-package:things:Thing[0] must be a token of the form pkg:module:name but got "Thing"`,
+		},
+		{
+			name:    "multiple problems",
+			config:  "version: v1\npackage: {}\nallowedPluralResources: [\"\"]\n",
+			wantErr: "invalid config: package.name is required; allowedPluralResources[0] must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.config))
+			if tt.wantErr == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/golang/glog"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// deduplicateTypes merges the types of the package that have the same spec
+// into a single type and rewrites the refs to the merged types. Merging
+// types can make other types identical, e.g. the types of two properties
+// whose only difference was the ref to one of the merged types, so it
+// runs until there is nothing left to merge.
+//
+// The identical types of a single module are merged into the one with the
+// most common name, or the shortest name if there is a tie. The identical
+// types of several modules are moved to the SharedTypesModule, if any.
+func (o *OpenAPIContext) deduplicateTypes(csharpNamespaces map[string]string) {
+	if !o.DeduplicateTypes {
+		return
+	}
+
+	merged := 0
+	for {
+		groups := make(map[string][]string)
+		for _, tok := range slices.Sorted(maps.Keys(o.Pkg.Types)) {
+			b, err := json.Marshal(o.Pkg.Types[tok])
+			if err != nil {
+				continue
+			}
+			groups[string(b)] = append(groups[string(b)], tok)
+		}
+
+		renames := make(map[string]string)
+		for _, sig := range slices.Sorted(maps.Keys(groups)) {
+			toks := groups[sig]
+			if len(toks) < 2 {
+				continue
+			}
+
+			canonicalTok := o.getCanonicalTypeToken(toks)
+			if _, ok := o.Pkg.Types[canonicalTok]; !ok {
+				o.Pkg.Types[canonicalTok] = o.Pkg.Types[toks[0]]
+
+				module := strings.Split(canonicalTok, ":")[1]
+				if _, ok := csharpNamespaces[module]; !ok {
+					csharpNamespaces[module] = moduleToPascalCase(module)
+				}
+			}
+			for _, tok := range toks {
+				if tok != canonicalTok {
+					renames[tok] = canonicalTok
+				}
+			}
+		}
+
+		if len(renames) == 0 {
+			break
+		}
+
+		for tok := range renames {
+			delete(o.Pkg.Types, tok)
+		}
+		rewritePackageTypeRefs(o.Pkg, renames)
+		merged += len(renames)
+	}
+
+	if merged > 0 {
+		glog.V(1).Infof("Merged %d identical types", merged)
+	}
+}
+
+// getCanonicalTypeToken returns the token of the type that the identical
+// types toks are merged into. toks must be sorted.
+func (o *OpenAPIContext) getCanonicalTypeToken(toks []string) string {
+	nameCounts := make(map[string]int)
+	modules := make(map[string]bool)
+	for _, tok := range toks {
+		nameCounts[getTokenName(tok)]++
+		modules[strings.Split(tok, ":")[1]] = true
+	}
+
+	names := slices.Sorted(maps.Keys(nameCounts))
+	slices.SortStableFunc(names, func(a, b string) int {
+		if nameCounts[a] != nameCounts[b] {
+			return nameCounts[b] - nameCounts[a]
+		}
+		return len(a) - len(b)
+	})
+	name := names[0]
+
+	if len(modules) > 1 && o.SharedTypesModule != "" {
+		sharedTok := o.Pkg.Name + ":" + o.SharedTypesModule + ":" + name
+		if _, ok := o.Pkg.Types[sharedTok]; !ok || slices.Contains(toks, sharedTok) {
+			return sharedTok
+		}
+	}
+
+	for _, tok := range toks {
+		if getTokenName(tok) == name {
+			return tok
+		}
+	}
+	return toks[0]
+}
+
+// rewritePackageTypeRefs replaces the refs to the types that are keys of
+// renames with refs to their new tokens in every property of the package.
+func rewritePackageTypeRefs(pkg *pschema.PackageSpec, renames map[string]string) {
+	rewriteObject := func(obj *pschema.ObjectTypeSpec) {
+		if obj != nil {
+			rewritePropertyTypeRefs(obj.Properties, renames)
+		}
+	}
+
+	for tok, typeSpec := range pkg.Types {
+		rewriteObject(&typeSpec.ObjectTypeSpec)
+		pkg.Types[tok] = typeSpec
+	}
+
+	for tok, resourceSpec := range pkg.Resources {
+		rewriteObject(&resourceSpec.ObjectTypeSpec)
+		rewritePropertyTypeRefs(resourceSpec.InputProperties, renames)
+		rewriteObject(resourceSpec.StateInputs)
+		pkg.Resources[tok] = resourceSpec
+	}
+
+	for tok, funcSpec := range pkg.Functions {
+		rewriteObject(funcSpec.Inputs)
+		rewriteObject(funcSpec.Outputs)
+		if funcSpec.ReturnType != nil {
+			rewriteObject(funcSpec.ReturnType.ObjectTypeSpec)
+			rewriteTypeRefs(funcSpec.ReturnType.TypeSpec, renames)
+		}
+		pkg.Functions[tok] = funcSpec
+	}
+
+	if pkg.Provider != nil {
+		rewriteObject(&pkg.Provider.ObjectTypeSpec)
+		rewritePropertyTypeRefs(pkg.Provider.InputProperties, renames)
+	}
+	rewritePropertyTypeRefs(pkg.Config.Variables, renames)
+}
+
+func rewritePropertyTypeRefs(props map[string]pschema.PropertySpec, renames map[string]string) {
+	for name, propSpec := range props {
+		rewriteTypeRefs(&propSpec.TypeSpec, renames)
+		props[name] = propSpec
+	}
+}
+
+// rewriteTypeRefs replaces the refs to the types that are keys of renames
+// in a type spec and in the type specs nested in it.
+func rewriteTypeRefs(t *pschema.TypeSpec, renames map[string]string) {
+	if t == nil {
+		return
+	}
+
+	rename := func(ref string) string {
+		if newTok, ok := renames[strings.TrimPrefix(ref, typesSchemaRefPrefix)]; ok && strings.HasPrefix(ref, typesSchemaRefPrefix) {
+			return typesSchemaRefPrefix + newTok
+		}
+		return ref
+	}
+
+	t.Ref = rename(t.Ref)
+	rewriteTypeRefs(t.Items, renames)
+	rewriteTypeRefs(t.AdditionalProperties, renames)
+	for i := range t.OneOf {
+		rewriteTypeRefs(&t.OneOf[i], renames)
+	}
+	if t.Discriminator != nil {
+		for value, ref := range t.Discriminator.Mapping {
+			t.Discriminator.Mapping[value] = rename(ref)
+		}
+	}
+}
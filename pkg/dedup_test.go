@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// gatherDedupTestPackage converts the dedup test doc into a new package
+// with DeduplicateTypes set.
+func gatherDedupTestPackage(t *testing.T, sharedTypesModule string) (*pschema.PackageSpec, map[string]string) {
+	doc, err := openapi3.NewLoader().LoadFromFile(filepath.Join("testdata", "dedup_openapi.yml"))
+	require.NoError(t, err)
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *doc,
+		Pkg: &pschema.PackageSpec{
+			Name:      packageName,
+			Types:     map[string]pschema.ComplexTypeSpec{},
+			Resources: map[string]pschema.ResourceSpec{},
+			Functions: map[string]pschema.FunctionSpec{},
+			Language:  map[string]pschema.RawMessage{},
+		},
+		DeduplicateTypes:  true,
+		SharedTypesModule: sharedTypesModule,
+	}
+
+	csharpNamespaces := map[string]string{"": providerNamespace}
+	_, _, err = openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	require.NoError(t, err)
+
+	return openAPICtx.Pkg, csharpNamespaces
+}
+
+func TestDeduplicateTypesSharedModule(t *testing.T) {
+	pkg, csharpNamespaces := gatherDedupTestPackage(t, "common")
+
+	coordinates := packageName + ":common:Coordinates"
+	berth := packageName + ":common:BerthProperties"
+	slip := packageName + ":common:SlipProperties"
+	assert.ElementsMatch(t, []string{coordinates, berth, slip}, slices.Collect(maps.Keys(pkg.Types)))
+	assert.Equal(t, "Common", csharpNamespaces["common"])
+
+	// The anchor types became identical to the slip type, which made
+	// the berth and mooring types identical in turn.
+	assert.Equal(t, typesSchemaRefPrefix+slip, pkg.Types[berth].Properties["anchor"].Ref)
+
+	pier := pkg.Resources[packageName+":piers/v2:Pier"]
+	raft := pkg.Resources[packageName+":rafts/v2:Raft"]
+	assert.Equal(t, typesSchemaRefPrefix+coordinates, pier.InputProperties["position"].Ref)
+	assert.Equal(t, typesSchemaRefPrefix+coordinates, raft.Properties["position"].Ref)
+	assert.Equal(t, typesSchemaRefPrefix+berth, pier.InputProperties["berth"].Ref)
+	assert.Equal(t, typesSchemaRefPrefix+berth, raft.InputProperties["mooring"].Ref)
+	assert.Equal(t, typesSchemaRefPrefix+slip, pier.Properties["slip"].Ref)
+}
+
+func TestDeduplicateTypes(t *testing.T) {
+	pkg, _ := gatherDedupTestPackage(t, "")
+
+	coordinates := packageName + ":piers/v2:Coordinates"
+	berth := packageName + ":piers/v2:BerthProperties"
+	slip := packageName + ":piers/v2:SlipProperties"
+	assert.ElementsMatch(t, []string{coordinates, berth, slip}, slices.Collect(maps.Keys(pkg.Types)))
+
+	raft := pkg.Resources[packageName+":rafts/v2:Raft"]
+	assert.Equal(t, typesSchemaRefPrefix+coordinates, raft.InputProperties["position"].Ref)
+	assert.Equal(t, typesSchemaRefPrefix+berth, raft.InputProperties["mooring"].Ref)
+}
+
+func TestRewriteTypeRefs(t *testing.T) {
+	renames := map[string]string{"pkg:a:Old": "pkg:b:New"}
+	typeSpec := &pschema.TypeSpec{
+		OneOf: []pschema.TypeSpec{
+			{Type: "array", Items: &pschema.TypeSpec{Ref: "#/types/pkg:a:Old"}},
+			{Type: "object", AdditionalProperties: &pschema.TypeSpec{Ref: "#/types/pkg:a:Other"}},
+		},
+		Discriminator: &pschema.DiscriminatorSpec{
+			PropertyName: "kind",
+			Mapping:      map[string]string{"old": "#/types/pkg:a:Old"},
+		},
+	}
+
+	rewriteTypeRefs(typeSpec, renames)
+
+	assert.Equal(t, "#/types/pkg:b:New", typeSpec.OneOf[0].Items.Ref)
+	assert.Equal(t, "#/types/pkg:a:Other", typeSpec.OneOf[1].AdditionalProperties.Ref)
+	assert.Equal(t, map[string]string{"old": "#/types/pkg:b:New"}, typeSpec.Discriminator.Mapping)
+}
@@ -0,0 +1,135 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/golang/glog"
+)
+
+// sentenceEndRegex matches the end of a sentence in a description.
+var sentenceEndRegex = regexp.MustCompile(`[.!?](\s+|$)|\n+`)
+
+// getDeprecationMessage returns the deprecation message for an operation,
+// schema or param, or an empty string if it isn't deprecated.
+//
+// The ExtDeprecatedMessage extension is used as-is and marks the element
+// as deprecated on its own, since Swagger 2.0 schemas and params can't be
+// marked as deprecated. Otherwise, the message is the sentence of the
+// description that mentions the deprecation, if any, or a generic message
+// for the kind of element. The deprecation of properties also depends on
+// the schemas they reference, see getPropertyDeprecationMessage.
+func getDeprecationMessage(kind string, deprecated bool, description string, extensions map[string]any) string {
+	if msg, ok := extensions[ExtDeprecatedMessage].(string); ok && strings.TrimSpace(msg) != "" {
+		return strings.TrimSpace(msg)
+	}
+
+	if !deprecated {
+		return ""
+	}
+
+	if sentence := getDeprecationSentence(description); sentence != "" {
+		return sentence
+	}
+
+	return fmt.Sprintf("This %s is deprecated.", kind)
+}
+
+// getPropertyDeprecationMessage returns the deprecation message for a
+// property schema. Pulumi object types can't be deprecated, so a property
+// whose items, values or single allOf member reference a deprecated schema
+// gets the deprecation message of the schema. A property that references
+// a deprecated schema directly already has the schema's deprecation.
+func getPropertyDeprecationMessage(s *openapi3.Schema) string {
+	if msg := getDeprecationMessage("property", s.Deprecated, s.Description, s.Extensions); msg != "" {
+		return msg
+	}
+
+	var refs []*openapi3.SchemaRef
+	if s.Items != nil {
+		refs = append(refs, s.Items)
+	}
+	if s.AdditionalProperties.Schema != nil {
+		refs = append(refs, s.AdditionalProperties.Schema)
+	}
+	if len(s.AllOf) == 1 {
+		refs = append(refs, s.AllOf[0])
+	}
+
+	for _, ref := range refs {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+
+		// Inline items and values, e.g. of nested arrays, can
+		// reference a deprecated schema too.
+		if ref.Ref == "" {
+			if msg := getPropertyDeprecationMessage(ref.Value); msg != "" {
+				return msg
+			}
+			continue
+		}
+
+		if msg := getDeprecationMessage("property", ref.Value.Deprecated, ref.Value.Description, ref.Value.Extensions); msg != "" {
+			return msg
+		}
+	}
+
+	return ""
+}
+
+// getDeprecationSentence returns the first sentence of the description
+// that mentions the deprecation. A bare "Deprecated." is combined with
+// the sentence that follows it, which usually says what to use instead.
+func getDeprecationSentence(description string) string {
+	sentences := sentenceEndRegex.Split(description, -1)
+	for i, sentence := range sentences {
+		sentence = strings.TrimSpace(sentence)
+		if !strings.Contains(strings.ToLower(sentence), "deprecated") {
+			continue
+		}
+
+		if strings.EqualFold(strings.TrimSuffix(sentence, ":"), "deprecated") && i+1 < len(sentences) {
+			if next := strings.TrimSpace(sentences[i+1]); next != "" {
+				return "Deprecated. " + next + "."
+			}
+		}
+
+		return sentence + "."
+	}
+
+	return ""
+}
+
+// removeDeprecatedOperations removes the deprecated operations from the
+// doc, and the paths that only have deprecated operations. The doc's
+// paths are copied so that the doc passed to the OpenAPIContext is not
+// modified.
+func (o *OpenAPIContext) removeDeprecatedOperations() {
+	paths := openapi3.NewPathsWithCapacity(o.Doc.Paths.Len())
+	paths.Extensions = o.Doc.Paths.Extensions
+
+	for _, path := range slices.Sorted(maps.Keys(o.Doc.Paths.Map())) {
+		pathItem := *o.Doc.Paths.Value(path)
+		pathItem.AdditionalOperations = maps.Clone(pathItem.AdditionalOperations)
+
+		for method, op := range pathItem.Operations() {
+			if op.Deprecated {
+				glog.V(2).Infof("Skipping deprecated operation %s %s", method, path)
+				pathItem.SetOperation(method, nil)
+			}
+		}
+
+		if len(pathItem.Operations()) > 0 {
+			paths.Set(path, &pathItem)
+		}
+	}
+
+	o.Doc.Paths = paths
+}
@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeprecation tests that deprecated operations, schemas, properties
+// and params have a deprecation message.
+func TestDeprecation(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "deprecation_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	_, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	resourceSpec, ok := testPulumiPkg.Resources["fake-package:stoves/v2:Stove"]
+	assert.Truef(t, ok, "Expected to find a resource called Stove: %v", testPulumiPkg.Resources)
+
+	assert.Equal(t, "Deprecated since v3, use the ovens API instead.", resourceSpec.DeprecationMessage)
+	assert.Empty(t, testPulumiPkg.Resources["fake-package:ovens/v2:Oven"].DeprecationMessage)
+
+	t.Run("Properties", func(t *testing.T) {
+		assert.Equal(t, "This property is deprecated.", resourceSpec.InputProperties["legacyMode"].DeprecationMessage)
+		assert.Equal(t, "This property is deprecated.", resourceSpec.Properties["legacyMode"].DeprecationMessage)
+		assert.Equal(t, "Use `burners` instead.", resourceSpec.InputProperties["burner"].DeprecationMessage)
+		assert.Empty(t, resourceSpec.InputProperties["burners"].DeprecationMessage)
+	})
+
+	t.Run("DeprecatedSchema", func(t *testing.T) {
+		assert.Equal(t, "Deprecated. Grates are included with every stove.", resourceSpec.InputProperties["grate"].DeprecationMessage)
+		assert.Equal(t, "Deprecated. Grates are included with every stove.", resourceSpec.InputProperties["spareGrates"].DeprecationMessage)
+	})
+
+	t.Run("Function", func(t *testing.T) {
+		funcSpec, ok := testPulumiPkg.Functions["fake-package:stoves/v2:getStove"]
+		assert.Truef(t, ok, "Expected to find a function called getStove: %v", testPulumiPkg.Functions)
+		assert.Equal(t, "Stoves are read-only now.", funcSpec.DeprecationMessage)
+		assert.Equal(t, "Deprecated. The API ignores it.", funcSpec.Inputs.Properties["verbose"].DeprecationMessage)
+	})
+
+	t.Run("SkipDeprecatedOperations", func(t *testing.T) {
+		openAPICtx := &OpenAPIContext{
+			Doc:                      *testOpenAPIDoc,
+			Pkg:                      &testPulumiPkg,
+			SkipDeprecatedOperations: true,
+		}
+
+		metadata, doc, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+		assert.Nil(t, err)
+
+		assert.NotContains(t, metadata.ResourceCRUDMap, "fake-package:stoves/v2:Stove")
+		assert.Contains(t, metadata.ResourceCRUDMap, "fake-package:ovens/v2:Oven")
+		assert.Nil(t, doc.Paths.Find("/v2/stoves"))
+		assert.Nil(t, doc.Paths.Find("/v2/stoves/{stove_id}"))
+
+		// The doc passed to the context is left as-is.
+		assert.NotNil(t, testOpenAPIDoc.Paths.Find("/v2/stoves").GetOperation(http.MethodPost))
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// getOperationDescription returns the description of the resource or
+// function for an operation, made of the operation's summary, its
+// description and a link to its external docs. The summary and the
+// description of the path item are used if the operation has neither.
+func getOperationDescription(pathItem *openapi3.PathItem, op *openapi3.Operation) string {
+	summary, description := op.Summary, op.Description
+	if summary == "" && description == "" {
+		summary, description = pathItem.Summary, pathItem.Description
+	}
+
+	return joinDescriptions(summary, description, getExternalDocsLink(op.ExternalDocs))
+}
+
+// getSchemaDescription returns the description of the property or type
+// for a schema, made of the schema's description, a link to its external
+// docs and its example, if the example is a simple value.
+func getSchemaDescription(s *openapi3.Schema) string {
+	return joinDescriptions(s.Description, getExternalDocsLink(s.ExternalDocs), getExampleText(s))
+}
+
+// joinDescriptions joins the non-empty parts as markdown paragraphs.
+// Parts that are already in another part, such as a summary that the
+// description starts with, are left out.
+func joinDescriptions(parts ...string) string {
+	var paragraphs []string
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		duplicate := false
+		for i, p := range paragraphs {
+			if strings.Contains(p, part) {
+				duplicate = true
+				break
+			}
+			if strings.Contains(part, p) {
+				paragraphs[i] = part
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			paragraphs = append(paragraphs, part)
+		}
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// getExternalDocsLink returns a markdown link to the external docs.
+func getExternalDocsLink(docs *openapi3.ExternalDocs) string {
+	if docs == nil || docs.URL == "" {
+		return ""
+	}
+
+	text := strings.TrimSuffix(strings.TrimSpace(docs.Description), ".")
+	if text == "" {
+		return fmt.Sprintf("See the [API documentation](%s).", docs.URL)
+	}
+
+	return fmt.Sprintf("[%s](%s)", text, docs.URL)
+}
+
+// getExampleText returns the example of a schema as inline code. Only
+// strings, numbers and booleans are used since objects and arrays don't
+// read well in a property description.
+func getExampleText(s *openapi3.Schema) string {
+	example := s.Example
+	if example == nil && len(s.Examples) > 0 {
+		example = s.Examples[0]
+	}
+
+	switch example.(type) {
+	case string, float64, int, int64, bool:
+		return fmt.Sprintf("Example: `%v`", example)
+	}
+
+	return ""
+}
+
+// setEnumValueDescriptions sets the descriptions of the enum values from
+// the ExtEnumDescriptions or the ExtMSEnum extension of the schema.
+func setEnumValueDescriptions(enumValues []pschema.EnumValueSpec, s openapi3.Schema) {
+	descriptions := make(map[string]string)
+
+	if list, ok := s.Extensions[ExtEnumDescriptions].([]any); ok {
+		for i, d := range list {
+			desc, ok := d.(string)
+			if ok && i < len(s.Enum) {
+				descriptions[fmt.Sprint(s.Enum[i])] = desc
+			}
+		}
+	}
+
+	if msEnum, ok := s.Extensions[ExtMSEnum].(map[string]any); ok {
+		values, _ := msEnum["values"].([]any)
+		for _, v := range values {
+			value, _ := v.(map[string]any)
+			desc, ok := value["description"].(string)
+			if ok && value["value"] != nil {
+				descriptions[fmt.Sprint(value["value"])] = desc
+			}
+		}
+	}
+
+	for i := range enumValues {
+		if desc, ok := descriptions[fmt.Sprint(enumValues[i].Value)]; ok {
+			enumValues[i].Description = strings.TrimSpace(desc)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDescriptions tests that descriptions are made of the summaries,
+// descriptions, external docs, examples and enum value descriptions.
+func TestDescriptions(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "descriptions_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	_, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	resourceSpec, ok := testPulumiPkg.Resources["fake-package:kites/v2:Kite"]
+	assert.Truef(t, ok, "Expected to find a resource called Kite: %v", testPulumiPkg.Resources)
+
+	assert.Equal(t, "A kite.\n\nCreate a kite with the given shape.\n\n[Kite docs](https://docs.fake.com/kites)", resourceSpec.Description)
+
+	t.Run("Function", func(t *testing.T) {
+		funcSpec, ok := testPulumiPkg.Functions["fake-package:kites/v2:getKite"]
+		assert.Truef(t, ok, "Expected to find a function called getKite: %v", testPulumiPkg.Functions)
+		assert.Equal(t, "A single kite", funcSpec.Description)
+	})
+
+	t.Run("Properties", func(t *testing.T) {
+		assert.Equal(t, "The color of the kite.\n\nExample: `red`", resourceSpec.InputProperties["color"].Description)
+		assert.Equal(t, "See the [API documentation](https://docs.fake.com/kites/tails).", resourceSpec.InputProperties["tail"].Description)
+	})
+
+	t.Run("EnumValues", func(t *testing.T) {
+		shapeType := testPulumiPkg.Types["fake-package:kites/v2:KiteShape"]
+		assert.Equal(t, "Four-sided.", shapeType.Enum[0].Description)
+		assert.Equal(t, "Triangular.", shapeType.Enum[1].Description)
+
+		windType := testPulumiPkg.Types["fake-package:kites/v2:KiteWind"]
+		assert.Empty(t, windType.Enum[0].Description)
+		assert.Equal(t, "Above 20 knots.", windType.Enum[1].Description)
+	})
+}
+
+func TestJoinDescriptions(t *testing.T) {
+	assert.Equal(t, "Create a droplet with the given size.", joinDescriptions("Create a droplet", "Create a droplet with the given size."))
+	assert.Equal(t, "List the droplets.\n\nOnly active droplets are returned.", joinDescriptions("List the droplets.", "", "Only active droplets are returned.", "List the droplets."))
+}
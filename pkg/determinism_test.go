@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+const determinismRuns = 10
+
+// generateTestPackage converts the doc at path into a new package
+// and returns the package spec and the metadata.
+func generateTestPackage(t *testing.T, path string) (*pschema.PackageSpec, *ProviderMetadata) {
+	t.Helper()
+
+	// Load the doc each time since the conversion can modify it.
+	doc, err := openapi3.NewLoader().LoadFromFile(path)
+	require.NoError(t, err)
+
+	pkg := pschema.PackageSpec{
+		Name:      packageName,
+		Types:     map[string]pschema.ComplexTypeSpec{},
+		Resources: map[string]pschema.ResourceSpec{},
+		Functions: map[string]pschema.FunctionSpec{},
+		Language:  map[string]pschema.RawMessage{},
+	}
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *doc,
+		Pkg: &pkg,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(map[string]string{"": providerNamespace})
+	require.NoError(t, err)
+
+	return &pkg, metadata
+}
+
+// generateTestSchema converts the doc at path into a new package
+// and returns the JSON of the package spec and the metadata.
+func generateTestSchema(t *testing.T, path string) ([]byte, []byte) {
+	t.Helper()
+
+	pkg, metadata := generateTestPackage(t, path)
+
+	pkgJSON, err := json.Marshal(pkg)
+	require.NoError(t, err)
+	metadataJSON, err := json.Marshal(metadata)
+	require.NoError(t, err)
+
+	return pkgJSON, metadataJSON
+}
+
+// Test to ensure that converting the same doc always produces
+// the same package spec and metadata.
+func TestDeterministicOutput(t *testing.T) {
+	fixtures := []string{
+		"determinism_openapi.yml",
+		"openapi.yml",
+		"bug_105_openapi.yml",
+		"resource_inputs_oneof_no_discriminator_openapi.yml",
+		"multiple_paths_using_same_refs_openapi.yml",
+		"prefix_enum_type_on_collision_openapi.yml",
+		"header_params_openapi.yml",
+		"long_running_operations_openapi.yml",
+		"binary_openapi.yml",
+		"validation_openapi.yml",
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+			path := filepath.Join("testdata", fixture)
+			wantPkg, wantMetadata := generateTestSchema(t, path)
+
+			for i := 1; i < determinismRuns; i++ {
+				gotPkg, gotMetadata := generateTestSchema(t, path)
+				assert.Equal(t, string(wantPkg), string(gotPkg), "package spec changed on run %d", i+1)
+				assert.Equal(t, string(wantMetadata), string(gotMetadata), "metadata changed on run %d", i+1)
+			}
+		})
+	}
+}
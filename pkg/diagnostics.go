@@ -0,0 +1,159 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity level of a Diagnostic.
+type Severity string
+
+const (
+	// SeverityWarning indicates a problem that did not prevent
+	// the conversion but likely produced an unexpected result.
+	SeverityWarning Severity = "warning"
+	// SeverityError indicates a problem that caused an operation,
+	// resource or property to be skipped.
+	SeverityError Severity = "error"
+)
+
+// DiagnosticCode identifies the kind of problem a Diagnostic reports.
+type DiagnosticCode string
+
+const (
+	// CodeMissingOperationID is reported for operations without an operationId.
+	CodeMissingOperationID DiagnosticCode = "missing-operation-id"
+	// CodeMissingRequestSchema is reported for operations whose request body
+	// does not have a JSON schema.
+	CodeMissingRequestSchema DiagnosticCode = "missing-request-schema"
+	// CodeMissingSchema is reported when a referenced schema cannot be found.
+	CodeMissingSchema DiagnosticCode = "missing-schema"
+	// CodeUnsupportedSchema is reported when a schema cannot be converted
+	// to a Pulumi type.
+	CodeUnsupportedSchema DiagnosticCode = "unsupported-schema"
+	// CodeNameOverrideConflict is reported when two different API names map
+	// to the same SDK name.
+	CodeNameOverrideConflict DiagnosticCode = "name-override-conflict"
+	// CodeInvalidAllOf is reported for allOf definitions with members that
+	// are neither refs nor objects.
+	CodeInvalidAllOf DiagnosticCode = "invalid-all-of"
+	// CodeInvalidExtension is reported for `x-pulumi-*` extensions with
+	// an unexpected value or in a place where they can't be applied.
+	CodeInvalidExtension DiagnosticCode = "invalid-extension"
+	// CodeUnsupportedLongRunningOperation is reported for async operations
+	// that a provider has no way to wait for.
+	CodeUnsupportedLongRunningOperation DiagnosticCode = "unsupported-long-running-operation"
+	// CodeTokenDrift is reported when the token generated for an operation
+	// differs from the one in the NamingLock, or when a locked operation
+	// no longer exists.
+	CodeTokenDrift DiagnosticCode = "token-drift"
+	// CodeUnknownResourceToken is reported for options that refer to
+	// a resource token that wasn't generated.
+	CodeUnknownResourceToken DiagnosticCode = "unknown-resource-token"
+	// CodeTypeCollision is reported when an object type has the same
+	// token as another type with a different spec and the
+	// TypeCollisionStrategy is TypeCollisionStrategyError.
+	CodeTypeCollision DiagnosticCode = "type-collision"
+	// CodeTokenCollision is reported when several OpenAPI docs generate
+	// different specs for the same token.
+	CodeTokenCollision DiagnosticCode = "token-collision"
+)
+
+// Diagnostic is a single problem found while converting an OpenAPI
+// spec to a Pulumi schema.
+type Diagnostic struct {
+	Severity Severity       `json:"severity"`
+	Code     DiagnosticCode `json:"code"`
+	// Pointer is the JSON pointer to the location in the OpenAPI doc
+	// where the problem was found, e.g. `/paths/~1v2~1droplets/post`.
+	Pointer string `json:"pointer,omitempty"`
+	// Token is the Pulumi type token affected by the problem, if any.
+	Token   string `json:"token,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s [%s]", d.Severity, d.Code))
+	if d.Pointer != "" {
+		b.WriteString(" " + d.Pointer)
+	}
+	if d.Token != "" {
+		b.WriteString(" (" + d.Token + ")")
+	}
+	b.WriteString(": " + d.Message)
+	return b.String()
+}
+
+// Diagnostics is a list of problems found during a conversion. It
+// implements the error interface so that it can be returned as-is
+// when the list contains errors.
+type Diagnostics []Diagnostic
+
+// HasErrors returns true if any of the diagnostics is an error.
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns only the diagnostics with the error severity.
+func (d Diagnostics) Errors() Diagnostics {
+	var errs Diagnostics
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			errs = append(errs, diag)
+		}
+	}
+	return errs
+}
+
+func (d Diagnostics) Error() string {
+	errs := d.Errors()
+	lines := make([]string, 0, len(errs))
+	for _, diag := range errs {
+		lines = append(lines, diag.String())
+	}
+	return fmt.Sprintf("%d error(s) found while converting the OpenAPI spec:\n%s", len(errs), strings.Join(lines, "\n"))
+}
+
+// diagnosticsCollector accumulates diagnostics during a conversion.
+type diagnosticsCollector struct {
+	// strict promotes warnings to errors.
+	strict bool
+	diags  Diagnostics
+}
+
+func (c *diagnosticsCollector) add(severity Severity, code DiagnosticCode, pointer, token, format string, args ...interface{}) {
+	if c.strict {
+		severity = SeverityError
+	}
+
+	c.diags = append(c.diags, Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Pointer:  pointer,
+		Token:    token,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *diagnosticsCollector) warnf(code DiagnosticCode, pointer, token, format string, args ...interface{}) {
+	c.add(SeverityWarning, code, pointer, token, format, args...)
+}
+
+func (c *diagnosticsCollector) errorf(code DiagnosticCode, pointer, token, format string, args ...interface{}) {
+	c.add(SeverityError, code, pointer, token, format, args...)
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// operationPointer returns the JSON pointer for an operation in the OpenAPI doc.
+func operationPointer(path, method string) string {
+	return "/paths/" + jsonPointerEscaper.Replace(path) + "/" + strings.ToLower(method)
+}
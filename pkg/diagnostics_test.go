@@ -0,0 +1,89 @@
+package pkg
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiagnostics tests that problems in the OpenAPI spec are
+// collected as diagnostics instead of stopping the conversion
+// at the first problem.
+func TestDiagnostics(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "diagnostics_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, metadata)
+
+	var diags Diagnostics
+	assert.True(t, errors.As(err, &diags), "Expected the error to be of type Diagnostics: %v", err)
+	assert.Len(t, diags.Errors(), 4)
+
+	assert.Contains(t, diags, Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeMissingOperationID,
+		Pointer:  "/paths/~1v2~1noOperationId/post",
+		Message:  "operationId is missing for path POST /v2/noOperationId",
+	})
+	// The problems of every operation of a path are reported.
+	assert.Contains(t, diags, Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeMissingOperationID,
+		Pointer:  "/paths/~1v2~1noOperationId/get",
+		Message:  "operationId is missing for path GET /v2/noOperationId",
+	})
+	assert.Contains(t, diags, Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeMissingRequestSchema,
+		Pointer:  "/paths/~1v2~1noPatchSchema~1{id}/patch",
+		Message:  "path /v2/noPatchSchema/{id} has no schema definition for Patch method",
+	})
+	assert.Contains(t, diags, Diagnostic{
+		Severity: SeverityWarning,
+		Code:     CodeMissingSchema,
+		Pointer:  "/paths/~1v2~1fakeResource/post",
+		Token:    "fake-package:fakeresource/v2:FakeResource",
+		Message:  "schema not found for required property missing_prop",
+	})
+
+	// The valid resource is still converted.
+	_, ok := testPulumiPkg.Resources["fake-package:fakeresource/v2:FakeResource"]
+	assert.True(t, ok, "Expected to find a resource called FakeResource")
+
+	// The valid operations of a path with an invalid one
+	// are still converted.
+	_, ok = testPulumiPkg.Resources["fake-package:widgets/v2:Widget"]
+	assert.True(t, ok, "Expected to find a resource called Widget")
+
+	t.Run("Strict", func(t *testing.T) {
+		openAPICtx := &OpenAPIContext{
+			Doc:    *testOpenAPIDoc,
+			Pkg:    &testPulumiPkg,
+			Strict: true,
+		}
+
+		_, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+		assert.Error(t, err)
+
+		diags := openAPICtx.Diagnostics()
+		assert.Len(t, diags.Errors(), 5)
+		assert.Contains(t, diags, Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeMissingSchema,
+			Pointer:  "/paths/~1v2~1fakeResource/post",
+			Token:    "fake-package:fakeresource/v2:FakeResource",
+			Message:  "schema not found for required property missing_prop",
+		})
+	})
+}
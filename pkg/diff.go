@@ -0,0 +1,495 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// ChangeKind classifies a Change by whether it breaks the programs
+// that use the previous version of the generated SDKs.
+type ChangeKind string
+
+const (
+	// ChangeKindBreaking indicates a change that requires the users
+	// of the SDKs to change their programs.
+	ChangeKindBreaking ChangeKind = "breaking"
+	// ChangeKindNonBreaking indicates a change that existing programs
+	// don't notice, such as a new resource or a new optional input.
+	ChangeKindNonBreaking ChangeKind = "non-breaking"
+)
+
+// ChangeCode identifies the kind of change a Change reports.
+type ChangeCode string
+
+const (
+	ChangeResourceAdded   ChangeCode = "resource-added"
+	ChangeResourceRemoved ChangeCode = "resource-removed"
+	ChangeResourceRenamed ChangeCode = "resource-renamed"
+	ChangeFunctionAdded   ChangeCode = "function-added"
+	ChangeFunctionRemoved ChangeCode = "function-removed"
+	ChangeFunctionRenamed ChangeCode = "function-renamed"
+	ChangeTypeAdded       ChangeCode = "type-added"
+	ChangeTypeRemoved     ChangeCode = "type-removed"
+	ChangeTypeRenamed     ChangeCode = "type-renamed"
+
+	ChangePropertyAdded    ChangeCode = "property-added"
+	ChangePropertyRemoved  ChangeCode = "property-removed"
+	ChangePropertyRequired ChangeCode = "property-became-required"
+	ChangePropertyOptional ChangeCode = "property-became-optional"
+	// ChangeTypeChanged is reported for properties, function results
+	// and enums whose type changed.
+	ChangeTypeChanged ChangeCode = "type-changed"
+
+	ChangeEnumValueAdded   ChangeCode = "enum-value-added"
+	ChangeEnumValueRemoved ChangeCode = "enum-value-removed"
+)
+
+// Change is a single difference between two versions of a Pulumi
+// package generated from an OpenAPI spec.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	Code ChangeCode `json:"code"`
+	// Token is the token of the affected resource, function or type
+	// in the old package, or in the new one if it was added.
+	Token string `json:"token"`
+	// Property is the affected property. Resource and function
+	// properties are prefixed with `inputs.` or `outputs.`. Empty
+	// if the change is for the resource, function or type itself.
+	Property string `json:"property,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (c Change) String() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s [%s] %s", c.Kind, c.Code, c.Token))
+	if c.Property != "" {
+		b.WriteString(" " + c.Property)
+	}
+	b.WriteString(": " + c.Message)
+	return b.String()
+}
+
+// Changes is the list of differences between two versions of a package.
+type Changes []Change
+
+// HasBreaking returns true if any of the changes is breaking.
+func (c Changes) HasBreaking() bool {
+	for _, change := range c {
+		if change.Kind == ChangeKindBreaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Breaking returns only the breaking changes.
+func (c Changes) Breaking() Changes {
+	var breaking Changes
+	for _, change := range c {
+		if change.Kind == ChangeKindBreaking {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+// DiffSchemas compares the package spec and provider metadata generated
+// from an old and a new version of an OpenAPI spec, and returns the
+// changes classified as breaking or non-breaking for the users of the
+// generated SDKs. The metadata is optional and may be nil.
+//
+// A removed resource is considered renamed if an added resource has the
+// same create endpoint in the metadata. Otherwise, a removed resource,
+// function or type is considered renamed if a single added one has the
+// same spec, ignoring descriptions. Refs to renamed types are not
+// reported as type changes.
+func DiffSchemas(oldPkg, newPkg *pschema.PackageSpec, oldMetadata, newMetadata *ProviderMetadata) Changes {
+	d := &schemaDiff{}
+
+	d.renamedTypes = diffTokens(d, oldPkg.Types, newPkg.Types, tokenChangeCodes{
+		kind: "type", added: ChangeTypeAdded, removed: ChangeTypeRemoved, renamed: ChangeTypeRenamed,
+	}, nil, nil)
+	renamedResources := diffTokens(d, oldPkg.Resources, newPkg.Resources, tokenChangeCodes{
+		kind: "resource", added: ChangeResourceAdded, removed: ChangeResourceRemoved, renamed: ChangeResourceRenamed,
+	}, createEndpointKey(oldMetadata), createEndpointKey(newMetadata))
+	renamedFunctions := diffTokens(d, oldPkg.Functions, newPkg.Functions, tokenChangeCodes{
+		kind: "function", added: ChangeFunctionAdded, removed: ChangeFunctionRemoved, renamed: ChangeFunctionRenamed,
+	}, nil, nil)
+
+	for _, oldTok := range slices.Sorted(maps.Keys(oldPkg.Types)) {
+		if newTok, ok := getNewToken(newPkg.Types, d.renamedTypes, oldTok); ok {
+			d.diffType(oldTok, oldPkg.Types[oldTok], newPkg.Types[newTok])
+		}
+	}
+
+	if oldPkg.Provider != nil && newPkg.Provider != nil {
+		tok := "pulumi:providers:" + newPkg.Name
+		d.diffProperties(tok, "inputs.", oldPkg.Provider.InputProperties, newPkg.Provider.InputProperties,
+			oldPkg.Provider.RequiredInputs, newPkg.Provider.RequiredInputs, true)
+	}
+
+	for _, oldTok := range slices.Sorted(maps.Keys(oldPkg.Resources)) {
+		newTok, ok := getNewToken(newPkg.Resources, renamedResources, oldTok)
+		if !ok {
+			continue
+		}
+
+		oldRes, newRes := oldPkg.Resources[oldTok], newPkg.Resources[newTok]
+		d.diffProperties(oldTok, "inputs.", oldRes.InputProperties, newRes.InputProperties, oldRes.RequiredInputs, newRes.RequiredInputs, true)
+		d.diffProperties(oldTok, "outputs.", oldRes.Properties, newRes.Properties, oldRes.Required, newRes.Required, false)
+	}
+
+	for _, oldTok := range slices.Sorted(maps.Keys(oldPkg.Functions)) {
+		if newTok, ok := getNewToken(newPkg.Functions, renamedFunctions, oldTok); ok {
+			d.diffFunction(oldTok, oldPkg.Functions[oldTok], newPkg.Functions[newTok])
+		}
+	}
+
+	return d.changes
+}
+
+// schemaDiff accumulates the changes found by DiffSchemas.
+type schemaDiff struct {
+	// renamedTypes is a map of the old tokens of the renamed
+	// types to their new tokens.
+	renamedTypes map[string]string
+	changes      Changes
+}
+
+// tokenChangeCodes are the codes of the changes reported by
+// diffTokens for a kind of token.
+type tokenChangeCodes struct {
+	kind                    string
+	added, removed, renamed ChangeCode
+}
+
+func (d *schemaDiff) add(kind ChangeKind, code ChangeCode, token, property, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Kind:     kind,
+		Code:     code,
+		Token:    token,
+		Property: property,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// diffTokens reports the removed, renamed and added tokens of the specs,
+// and returns a map of the old tokens of the renamed ones to their new
+// tokens. Removed and added tokens are matched by the keys returned by
+// oldKey and newKey, if not nil, and then by their spec signature.
+func diffTokens[T any](d *schemaDiff, oldSpecs, newSpecs map[string]T, codes tokenChangeCodes, oldKey, newKey func(string) string) map[string]string {
+	var removed, added []string
+	for _, tok := range slices.Sorted(maps.Keys(oldSpecs)) {
+		if _, ok := newSpecs[tok]; !ok {
+			removed = append(removed, tok)
+		}
+	}
+	for _, tok := range slices.Sorted(maps.Keys(newSpecs)) {
+		if _, ok := oldSpecs[tok]; !ok {
+			added = append(added, tok)
+		}
+	}
+
+	renamed := make(map[string]string)
+	matched := codegen.NewStringSet()
+	match := func(key func(string, bool) string) {
+		for _, oldTok := range removed {
+			k := key(oldTok, true)
+			if k == "" || renamed[oldTok] != "" {
+				continue
+			}
+
+			var candidates []string
+			for _, newTok := range added {
+				if !matched.Has(newTok) && key(newTok, false) == k {
+					candidates = append(candidates, newTok)
+				}
+			}
+			if len(candidates) == 1 {
+				renamed[oldTok] = candidates[0]
+				matched.Add(candidates[0])
+			}
+		}
+	}
+
+	if oldKey != nil && newKey != nil {
+		match(func(tok string, old bool) string {
+			if old {
+				return oldKey(tok)
+			}
+			return newKey(tok)
+		})
+	}
+	match(func(tok string, old bool) string {
+		if old {
+			return getSpecSignature(oldSpecs[tok])
+		}
+		return getSpecSignature(newSpecs[tok])
+	})
+
+	for _, tok := range removed {
+		if newTok, ok := renamed[tok]; ok {
+			d.add(ChangeKindBreaking, codes.renamed, tok, "", "%s renamed to %s", codes.kind, newTok)
+			continue
+		}
+		d.add(ChangeKindBreaking, codes.removed, tok, "", "%s removed", codes.kind)
+	}
+	for _, tok := range added {
+		if !matched.Has(tok) {
+			d.add(ChangeKindNonBreaking, codes.added, tok, "", "%s added", codes.kind)
+		}
+	}
+
+	return renamed
+}
+
+// getNewToken returns the token in the new specs of the old token,
+// taking renames into account, and whether it is in the new specs.
+func getNewToken[T any](newSpecs map[string]T, renamed map[string]string, oldTok string) (string, bool) {
+	if newTok, ok := renamed[oldTok]; ok {
+		return newTok, true
+	}
+
+	_, ok := newSpecs[oldTok]
+	return oldTok, ok
+}
+
+// createEndpointKey returns a func that returns the create endpoint of
+// a resource in the metadata, or an empty string if it has none.
+func createEndpointKey(metadata *ProviderMetadata) func(string) string {
+	if metadata == nil {
+		return nil
+	}
+
+	return func(tok string) string {
+		crud, ok := metadata.ResourceCRUDMap[tok]
+		if !ok || crud == nil {
+			return ""
+		}
+
+		switch {
+		case crud.Operations.C != nil:
+			return crud.Operations.C.Method + " " + crud.Operations.C.Path
+		case crud.C != nil:
+			return "POST " + *crud.C
+		case crud.Operations.P != nil:
+			return crud.Operations.P.Method + " " + crud.Operations.P.Path
+		case crud.P != nil:
+			return "PUT " + *crud.P
+		}
+
+		return ""
+	}
+}
+
+// specNameMaps are the fields of the specs whose keys are names, such
+// as property names, instead of the fields of a spec.
+var specNameMaps = codegen.NewStringSet("properties", "inputProperties", "language", "mapping", "methods")
+
+// getSpecSignature returns the JSON of a spec without the descriptions
+// and the deprecation messages, which don't change the SDKs' API.
+func getSpecSignature(spec any) string {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return ""
+	}
+
+	// A property can be named `description`, so the keys
+	// of the name maps are never stripped.
+	var strip func(v any, isNameMap bool)
+	strip = func(v any, isNameMap bool) {
+		switch v := v.(type) {
+		case map[string]any:
+			if !isNameMap {
+				delete(v, "description")
+				delete(v, "deprecationMessage")
+			}
+			for k, child := range v {
+				strip(child, !isNameMap && specNameMaps.Has(k))
+			}
+		case []any:
+			for _, child := range v {
+				strip(child, false)
+			}
+		}
+	}
+	strip(v, false)
+
+	// Map keys are sorted when marshaled, so the
+	// signatures of equal specs are the same.
+	b, _ = json.Marshal(v)
+	return string(b)
+}
+
+// diffType reports the changes of the properties of an object type, and
+// the changes of the values of an enum type. Object types can be used
+// both as inputs and outputs, so they are compared as inputs, for which
+// removing a property or making it required is breaking.
+func (d *schemaDiff) diffType(tok string, oldType, newType pschema.ComplexTypeSpec) {
+	oldIsEnum, newIsEnum := len(oldType.Enum) > 0, len(newType.Enum) > 0
+	if oldIsEnum != newIsEnum || (oldIsEnum && oldType.Type != newType.Type) {
+		d.add(ChangeKindBreaking, ChangeTypeChanged, tok, "", "type changed from %s to %s", getComplexTypeKind(oldType), getComplexTypeKind(newType))
+		return
+	}
+
+	if !oldIsEnum {
+		d.diffProperties(tok, "", oldType.Properties, newType.Properties, oldType.Required, newType.Required, true)
+		return
+	}
+
+	newValues := make(map[string]bool)
+	for _, v := range newType.Enum {
+		newValues[fmt.Sprint(v.Value)] = true
+	}
+	oldValues := make(map[string]bool)
+	for _, v := range oldType.Enum {
+		value := fmt.Sprint(v.Value)
+		oldValues[value] = true
+		if !newValues[value] {
+			d.add(ChangeKindBreaking, ChangeEnumValueRemoved, tok, "", "enum value %q removed", value)
+		}
+	}
+	for _, v := range newType.Enum {
+		if value := fmt.Sprint(v.Value); !oldValues[value] {
+			d.add(ChangeKindNonBreaking, ChangeEnumValueAdded, tok, "", "enum value %q added", value)
+		}
+	}
+}
+
+func getComplexTypeKind(t pschema.ComplexTypeSpec) string {
+	if len(t.Enum) > 0 {
+		return t.Type + " enum"
+	}
+	return typeObject
+}
+
+// diffFunction reports the changes of the inputs and the result of a
+// function. The result is compared as outputs.
+func (d *schemaDiff) diffFunction(tok string, oldFunc, newFunc pschema.FunctionSpec) {
+	var oldInputs, newInputs pschema.ObjectTypeSpec
+	if oldFunc.Inputs != nil {
+		oldInputs = *oldFunc.Inputs
+	}
+	if newFunc.Inputs != nil {
+		newInputs = *newFunc.Inputs
+	}
+	d.diffProperties(tok, "inputs.", oldInputs.Properties, newInputs.Properties, oldInputs.Required, newInputs.Required, true)
+
+	oldObject, oldTypeSpec := getFunctionResult(oldFunc)
+	newObject, newTypeSpec := getFunctionResult(newFunc)
+	switch {
+	case oldObject != nil && newObject != nil:
+		d.diffProperties(tok, "outputs.", oldObject.Properties, newObject.Properties, oldObject.Required, newObject.Required, false)
+	case oldTypeSpec != nil && newTypeSpec != nil:
+		oldType, newType := d.getTypeName(oldTypeSpec, true), d.getTypeName(newTypeSpec, false)
+		if oldType != newType {
+			d.add(ChangeKindBreaking, ChangeTypeChanged, tok, "", "result type changed from %s to %s", oldType, newType)
+		}
+	case oldObject != nil || oldTypeSpec != nil:
+		d.add(ChangeKindBreaking, ChangeTypeChanged, tok, "", "result type changed")
+	}
+}
+
+// getFunctionResult returns either the object or the type of the
+// result of a function, or neither if it doesn't return anything.
+func getFunctionResult(f pschema.FunctionSpec) (*pschema.ObjectTypeSpec, *pschema.TypeSpec) {
+	if f.ReturnType != nil {
+		return f.ReturnType.ObjectTypeSpec, f.ReturnType.TypeSpec
+	}
+	return f.Outputs, nil
+}
+
+// diffProperties reports the removed, added and changed properties.
+//
+// For inputs, adding a required property and making a property required
+// are breaking. For outputs, making a property optional is breaking since
+// programs that use its value must now check that it's set.
+func (d *schemaDiff) diffProperties(tok, prefix string, oldProps, newProps map[string]pschema.PropertySpec, oldRequired, newRequired []string, inputs bool) {
+	oldReq, newReq := codegen.NewStringSet(oldRequired...), codegen.NewStringSet(newRequired...)
+
+	for _, name := range slices.Sorted(maps.Keys(oldProps)) {
+		newProp, ok := newProps[name]
+		if !ok {
+			d.add(ChangeKindBreaking, ChangePropertyRemoved, tok, prefix+name, "property removed")
+			continue
+		}
+
+		oldProp := oldProps[name]
+		oldType, newType := d.getTypeName(&oldProp.TypeSpec, true), d.getTypeName(&newProp.TypeSpec, false)
+		if oldType != newType {
+			d.add(ChangeKindBreaking, ChangeTypeChanged, tok, prefix+name, "type changed from %s to %s", oldType, newType)
+		}
+
+		switch {
+		case !oldReq.Has(name) && newReq.Has(name):
+			kind := ChangeKindNonBreaking
+			if inputs {
+				kind = ChangeKindBreaking
+			}
+			d.add(kind, ChangePropertyRequired, tok, prefix+name, "property became required")
+		case oldReq.Has(name) && !newReq.Has(name):
+			kind := ChangeKindNonBreaking
+			if !inputs {
+				kind = ChangeKindBreaking
+			}
+			d.add(kind, ChangePropertyOptional, tok, prefix+name, "property became optional")
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(newProps)) {
+		if _, ok := oldProps[name]; ok {
+			continue
+		}
+
+		if inputs && newReq.Has(name) {
+			d.add(ChangeKindBreaking, ChangePropertyAdded, tok, prefix+name, "required property added")
+			continue
+		}
+		d.add(ChangeKindNonBreaking, ChangePropertyAdded, tok, prefix+name, "property added")
+	}
+}
+
+// getTypeName returns a readable name for a type spec, such as
+// `array<string>` or `map<#/types/pkg:module:Type>`. The refs to
+// renamed types in the old package use the new type tokens.
+func (d *schemaDiff) getTypeName(t *pschema.TypeSpec, old bool) string {
+	if t == nil {
+		return ""
+	}
+
+	switch {
+	case t.Ref != "":
+		tok := strings.TrimPrefix(t.Ref, typesSchemaRefPrefix)
+		if newTok, ok := d.renamedTypes[tok]; ok && old {
+			return typesSchemaRefPrefix + newTok
+		}
+		return t.Ref
+	case len(t.OneOf) > 0:
+		names := make([]string, 0, len(t.OneOf))
+		for i := range t.OneOf {
+			names = append(names, d.getTypeName(&t.OneOf[i], old))
+		}
+		return "oneOf<" + strings.Join(names, "|") + ">"
+	case t.Type == openapi3.TypeArray:
+		return "array<" + d.getTypeName(t.Items, old) + ">"
+	case t.Type == typeObject && t.AdditionalProperties != nil:
+		return "map<" + d.getTypeName(t.AdditionalProperties, old) + ">"
+	}
+
+	return t.Type
+}
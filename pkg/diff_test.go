@@ -0,0 +1,154 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// findChange returns the change with the code for the token and
+// property, if any.
+func findChange(changes Changes, code ChangeCode, token, property string) *Change {
+	for i, c := range changes {
+		if c.Code == code && c.Token == token && c.Property == property {
+			return &changes[i]
+		}
+	}
+	return nil
+}
+
+func TestDiffSchemas(t *testing.T) {
+	oldPkg, oldMetadata := generateTestPackage(t, filepath.Join("testdata", "diff_old_openapi.yml"))
+	newPkg, newMetadata := generateTestPackage(t, filepath.Join("testdata", "diff_new_openapi.yml"))
+
+	changes := DiffSchemas(oldPkg, newPkg, oldMetadata, newMetadata)
+	assert.True(t, changes.HasBreaking())
+
+	lampTok := packageName + ":lamps/v2:Lamp"
+	tests := []struct {
+		code     ChangeCode
+		token    string
+		property string
+		kind     ChangeKind
+	}{
+		{ChangeResourceRemoved, packageName + ":dimmers/v2:Dimmer", "", ChangeKindBreaking},
+		{ChangeResourceRenamed, packageName + ":knobs/v2:Knob", "", ChangeKindBreaking},
+		{ChangeResourceAdded, packageName + ":timers/v2:Timer", "", ChangeKindNonBreaking},
+		{ChangeEnumValueRemoved, packageName + ":lamps/v2:LampColor", "", ChangeKindBreaking},
+		{ChangeEnumValueAdded, packageName + ":lamps/v2:LampColor", "", ChangeKindNonBreaking},
+		{ChangePropertyRequired, lampTok, "inputs.bulbType", ChangeKindBreaking},
+		{ChangePropertyRequired, lampTok, "outputs.bulbType", ChangeKindNonBreaking},
+		{ChangeTypeChanged, lampTok, "inputs.wattage", ChangeKindBreaking},
+		{ChangePropertyAdded, lampTok, "inputs.brightness", ChangeKindNonBreaking},
+		{ChangePropertyRemoved, lampTok, "outputs.status", ChangeKindBreaking},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code)+" "+tt.token+" "+tt.property, func(t *testing.T) {
+			c := findChange(changes, tt.code, tt.token, tt.property)
+			if assert.NotNil(t, c) {
+				assert.Equal(t, tt.kind, c.Kind)
+			}
+		})
+	}
+
+	renamed := findChange(changes, ChangeResourceRenamed, packageName+":knobs/v2:Knob", "")
+	assert.Equal(t, "resource renamed to "+packageName+":knobs/v2:Dial", renamed.Message)
+	// The renamed resource isn't also reported as removed and added.
+	assert.Nil(t, findChange(changes, ChangeResourceRemoved, packageName+":knobs/v2:Knob", ""))
+	assert.Nil(t, findChange(changes, ChangeResourceAdded, packageName+":knobs/v2:Dial", ""))
+}
+
+func TestDiffSchemasNoChanges(t *testing.T) {
+	oldPkg, oldMetadata := generateTestPackage(t, filepath.Join("testdata", "diff_old_openapi.yml"))
+	newPkg, newMetadata := generateTestPackage(t, filepath.Join("testdata", "diff_old_openapi.yml"))
+
+	changes := DiffSchemas(oldPkg, newPkg, oldMetadata, newMetadata)
+	assert.Empty(t, changes)
+	assert.False(t, changes.HasBreaking())
+}
+
+// Test to ensure that resources are matched by their spec when
+// there is no metadata.
+func TestDiffSchemasRenamedResourceWithoutMetadata(t *testing.T) {
+	oldPkg, _ := generateTestPackage(t, filepath.Join("testdata", "diff_old_openapi.yml"))
+	newPkg, _ := generateTestPackage(t, filepath.Join("testdata", "diff_new_openapi.yml"))
+
+	changes := DiffSchemas(oldPkg, newPkg, nil, nil)
+	renamed := findChange(changes, ChangeResourceRenamed, packageName+":knobs/v2:Knob", "")
+	if assert.NotNil(t, renamed) {
+		assert.Equal(t, "resource renamed to "+packageName+":knobs/v2:Dial", renamed.Message)
+	}
+}
+
+// Test to ensure that the refs to a renamed type are not
+// reported as type changes.
+func TestDiffSchemasRenamedTypeRefs(t *testing.T) {
+	pkgWithType := func(typeTok string) *pschema.PackageSpec {
+		return &pschema.PackageSpec{
+			Name: packageName,
+			Types: map[string]pschema.ComplexTypeSpec{
+				typeTok: {
+					ObjectTypeSpec: pschema.ObjectTypeSpec{
+						Type: typeObject,
+						Properties: map[string]pschema.PropertySpec{
+							"size": {TypeSpec: pschema.TypeSpec{Type: "integer"}},
+						},
+					},
+				},
+			},
+			Resources: map[string]pschema.ResourceSpec{
+				packageName + ":shelves/v2:Shelf": {
+					InputProperties: map[string]pschema.PropertySpec{
+						"bins": {TypeSpec: pschema.TypeSpec{
+							Type:  "array",
+							Items: &pschema.TypeSpec{Ref: typesSchemaRefPrefix + typeTok},
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	oldTok, newTok := packageName+":shelves/v2:Bin", packageName+":shelves/v2:ShelfBin"
+	changes := DiffSchemas(pkgWithType(oldTok), pkgWithType(newTok), nil, nil)
+
+	assert.Len(t, changes, 1)
+	renamed := findChange(changes, ChangeTypeRenamed, oldTok, "")
+	if assert.NotNil(t, renamed) {
+		assert.Equal(t, ChangeKindBreaking, renamed.Kind)
+		assert.Equal(t, "type renamed to "+newTok, renamed.Message)
+	}
+}
+
+// Test to ensure that only the descriptions of the specs are ignored
+// and not the properties that are named `description`.
+func TestGetSpecSignature(t *testing.T) {
+	resourceWith := func(description string, props map[string]pschema.PropertySpec) pschema.ResourceSpec {
+		return pschema.ResourceSpec{
+			ObjectTypeSpec: pschema.ObjectTypeSpec{
+				Description: description,
+				Properties:  props,
+			},
+			InputProperties: props,
+		}
+	}
+
+	name := map[string]pschema.PropertySpec{
+		"name": {TypeSpec: pschema.TypeSpec{Type: "string"}, Description: "The name."},
+	}
+	nameAndDescription := map[string]pschema.PropertySpec{
+		"name":        {TypeSpec: pschema.TypeSpec{Type: "string"}, Description: "The name."},
+		"description": {TypeSpec: pschema.TypeSpec{Type: "string"}},
+	}
+
+	assert.Equal(t,
+		getSpecSignature(resourceWith("A shelf.", name)),
+		getSpecSignature(resourceWith("A bookshelf.", name)))
+	assert.NotEqual(t,
+		getSpecSignature(resourceWith("A shelf.", name)),
+		getSpecSignature(resourceWith("A shelf.", nameAndDescription)))
+}
@@ -1,3 +1,45 @@
 package pkg
 
 const ExtSecretProp = "x-pulumi-secret" //nolint:gosec
+
+// ExtResourceName is an operation extension that pins the name of the
+// Pulumi resource that a CRUD operation maps to. It takes precedence
+// over the name derived from the operationId. For GET operations, the
+// name is also used for the get/list function names.
+const ExtResourceName = "x-pulumi-resource-name"
+
+// ExtModule is an operation extension that pins the module of the
+// Pulumi resource or function that an operation maps to. It takes
+// precedence over the module derived from the path. All operations
+// of a resource must use the same module.
+const ExtModule = "x-pulumi-module"
+
+// ExtAutoName is a property extension that marks the property of a
+// resource's request body that the provider can auto-name, even if
+// it isn't required. `false` prevents a property listed in
+// OpenAPIContext.AutoNameProperties from being auto-named. It can also
+// be an object with the `charset` allowed in the names, written as the
+// contents of a regex character class, e.g. `a-z0-9-`.
+const ExtAutoName = "x-pulumi-autoname"
+
+// ExtDeprecatedMessage is an extension with the deprecation message
+// of an operation, schema, property or param. It also marks them as
+// deprecated, since Swagger 2.0 can only mark operations as deprecated.
+const ExtDeprecatedMessage = "x-deprecated-message"
+
+// ExtEnumDescriptions is a schema extension with the descriptions
+// of the enum values, in the same order as the values.
+const ExtEnumDescriptions = "x-enum-descriptions"
+
+// ExtMSEnum is the AutoRest enum extension. Only the descriptions
+// of its `values` are used.
+const ExtMSEnum = "x-ms-enum"
+
+// ExtMSLongRunningOperation is the AutoRest operation extension that
+// marks an operation as long-running.
+const ExtMSLongRunningOperation = "x-ms-long-running-operation"
+
+// ExtMSLongRunningOperationOptions is the AutoRest operation extension
+// with the options of a long-running operation. Only `final-state-via`
+// is used to pick the header with the polling URL.
+const ExtMSLongRunningOperationOptions = "x-ms-long-running-operation-options"
@@ -0,0 +1,123 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// TestHeaderParams tests that header params are mapped to provider
+// config variables or to resource and function inputs.
+func TestHeaderParams(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "header_params_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	resourceToken := "fake-package:boats/v2:Boat"
+	listFuncToken := "fake-package:boats/v2:listBoats"
+
+	t.Run("Provider", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"xTenantId": "X-Tenant-Id"}, metadata.ProviderHeaderParamsMap)
+		assert.Equal(t, "The tenant that owns the resources.", testPulumiPkg.Config.Variables["xTenantId"].Description)
+		assert.Contains(t, testPulumiPkg.Provider.InputProperties, "xTenantId")
+
+		assert.NotContains(t, testPulumiPkg.Resources[resourceToken].InputProperties, "xTenantId")
+		assert.NotContains(t, testPulumiPkg.Functions[listFuncToken].Inputs.Properties, "xTenantId")
+	})
+
+	t.Run("ResourceInput", func(t *testing.T) {
+		resourceSpec := testPulumiPkg.Resources[resourceToken]
+		assert.Contains(t, resourceSpec.InputProperties, "xProject")
+		assert.Contains(t, resourceSpec.RequiredInputs, "xProject")
+		assert.NotContains(t, resourceSpec.InputProperties, "authorization")
+		assert.NotContains(t, resourceSpec.Properties, "xProject")
+
+		assert.Equal(t, map[string]string{"xProject": "X-Project", "ifMatch": "If-Match"}, metadata.HeaderParamsMap[resourceToken])
+
+		// Header params aren't in the PATCH request body,
+		// but they don't force a replacement.
+		assert.False(t, resourceSpec.InputProperties["xProject"].ReplaceOnChanges)
+		assert.False(t, resourceSpec.InputProperties["ifMatch"].ReplaceOnChanges)
+		assert.NotContains(t, metadata.ReplaceOnChangesMap[resourceToken], "xProject")
+		assert.NotContains(t, metadata.ReplaceOnChangesMap[resourceToken], "ifMatch")
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		assert.Contains(t, openAPICtx.Diagnostics(), Diagnostic{
+			Severity: SeverityWarning,
+			Code:     CodeUnsupportedSchema,
+			Pointer:  "/paths/~1v2~1boats/post",
+			Token:    resourceToken,
+			Message:  `header param "-" has no valid input name and was skipped`,
+		})
+	})
+
+	t.Run("FunctionInput", func(t *testing.T) {
+		inputs := testPulumiPkg.Functions[listFuncToken].Inputs
+		assert.Equal(t, "boolean", inputs.Properties["xTrace"].Type)
+		assert.Equal(t, "Traces the request.", inputs.Properties["xTrace"].Description)
+		assert.NotContains(t, inputs.Required, "xTrace")
+
+		assert.Equal(t, map[string]string{"xTrace": "X-Trace"}, metadata.HeaderParamsMap[listFuncToken])
+	})
+
+	t.Run("CallerMapsNotMutated", func(t *testing.T) {
+		variables := map[string]pschema.PropertySpec{}
+		inputs := map[string]pschema.PropertySpec{}
+		pkg := testPulumiPkg
+		pkg.Config.Variables = variables
+		pkg.Provider = &pschema.ResourceSpec{InputProperties: inputs}
+		pkg.Types = map[string]pschema.ComplexTypeSpec{}
+		pkg.Resources = map[string]pschema.ResourceSpec{}
+		pkg.Functions = map[string]pschema.FunctionSpec{}
+
+		openAPICtx := &OpenAPIContext{
+			Doc: *testOpenAPIDoc,
+			Pkg: &pkg,
+		}
+
+		_, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+		assert.Nil(t, err)
+
+		assert.Contains(t, pkg.Config.Variables, "xTenantId")
+		assert.Contains(t, pkg.Provider.InputProperties, "xTenantId")
+		assert.Empty(t, variables)
+		assert.Empty(t, inputs)
+	})
+
+	t.Run("HeaderParamsOverride", func(t *testing.T) {
+		openAPICtx := &OpenAPIContext{
+			Doc: *testOpenAPIDoc,
+			Pkg: &testPulumiPkg,
+			HeaderParams: map[string]HeaderParamTarget{
+				"x-project": HeaderParamTargetProvider,
+				"X-Trace":   HeaderParamTargetIgnore,
+				"If-Match":  HeaderParamTargetIgnore,
+			},
+		}
+
+		metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+		assert.Nil(t, err)
+
+		assert.Equal(t, map[string]string{
+			"xProject":  "X-Project",
+			"xTenantId": "X-Tenant-Id",
+		}, metadata.ProviderHeaderParamsMap)
+		assert.NotContains(t, metadata.HeaderParamsMap, resourceToken)
+		assert.NotContains(t, metadata.HeaderParamsMap, listFuncToken)
+		assert.NotContains(t, testPulumiPkg.Functions[listFuncToken].Inputs.Properties, "xTrace")
+	})
+}
@@ -0,0 +1,260 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// statusPropertyNames are the names of the output properties
+// that commonly hold the provisioning status of a resource,
+// in the order of preference.
+var statusPropertyNames = []string{"provisioningState", "status", "state", "lifecycleState"}
+
+// statusPropertyParents are the names of the output properties
+// whose object type can hold the status property instead of the
+// resource itself, for example, Azure's `properties.provisioningState`.
+var statusPropertyParents = []string{"properties"}
+
+// Terminal status values are matched case-insensitively. Each
+// operation has its own success values, since a resource that is
+// `active` has finished being created but not being deleted. All
+// other values, e.g. `running` or `provisioning`, mean that the
+// operation is still in progress.
+var (
+	createStatusValues  = codegen.NewStringSet("succeeded", "success", "successful", "completed", "complete", "done", "ready", "active", "available", "created")
+	updateStatusValues  = codegen.NewStringSet("succeeded", "success", "successful", "completed", "complete", "done", "ready", "active", "available", "updated")
+	deleteStatusValues  = codegen.NewStringSet("succeeded", "success", "successful", "completed", "complete", "done", "deleted")
+	failureStatusValues = codegen.NewStringSet("failed", "failure", "error", "errored", "canceled", "cancelled")
+)
+
+// pollingHeaders are the polling URL sources of the response
+// headers of an async operation, in the order of preference.
+var pollingHeaders = []PollingURLSource{
+	PollingURLSourceOperationLocationHeader,
+	PollingURLSourceAzureAsyncOperationHeader,
+	PollingURLSourceLocationHeader,
+}
+
+// pollingHeaderNames maps the polling URL sources to the name
+// of their response header.
+var pollingHeaderNames = map[PollingURLSource]string{
+	PollingURLSourceOperationLocationHeader:   "Operation-Location",
+	PollingURLSourceAzureAsyncOperationHeader: "Azure-AsyncOperation",
+	PollingURLSourceLocationHeader:            "Location",
+}
+
+// finalStateViaHeaders maps the `final-state-via` values of the
+// ExtMSLongRunningOperationOptions extension to the polling header.
+var finalStateViaHeaders = map[string]PollingURLSource{
+	"operation-location":    PollingURLSourceOperationLocationHeader,
+	"azure-async-operation": PollingURLSourceAzureAsyncOperationHeader,
+	"location":              PollingURLSourceLocationHeader,
+}
+
+// gatherLongRunningOperations detects the async create, update and
+// delete operations of the resources gathered from the API and
+// records how to wait for them to complete.
+//
+// An operation is async if it is marked with ExtMSLongRunningOperation
+// or if it responds with 202 Accepted. The polling URL comes from the
+// `Operation-Location`, `Azure-AsyncOperation` or `Location` header of
+// the response, otherwise the resource's read endpoint is polled.
+//
+// A resource with a status property whose enum has terminal values
+// is also treated as having an async create, since the API returns
+// the resource before it is ready.
+func (o *OpenAPIContext) gatherLongRunningOperations() {
+	for _, tok := range slices.Sorted(maps.Keys(o.resourceCRUDMap)) {
+		crudMap := o.resourceCRUDMap[tok]
+		resourceSpec, ok := o.Pkg.Resources[tok]
+		if !ok {
+			continue
+		}
+
+		lro := &LongRunningOperations{}
+		var statusValues []string
+		lro.StatusProperty, statusValues = o.getStatusProperty(resourceSpec.Properties)
+
+		hasReadEndpoint := crudMap.R != nil
+		lro.C = o.getLongRunningOperation(crudMap.Operations.C, hasReadEndpoint)
+		lro.U = o.getLongRunningOperation(crudMap.Operations.U, hasReadEndpoint)
+		lro.D = o.getLongRunningOperation(crudMap.Operations.D, hasReadEndpoint)
+		lro.P = o.getLongRunningOperation(crudMap.Operations.P, hasReadEndpoint)
+
+		if lro.C == nil && lro.StatusProperty != "" && hasReadEndpoint {
+			lro.C = &LongRunningOperation{PollingURLSource: PollingURLSourceReadEndpoint}
+		}
+
+		if lro.C == nil && lro.U == nil && lro.D == nil && lro.P == nil {
+			continue
+		}
+
+		setTerminalStatusValues(lro.C, statusValues, createStatusValues)
+		setTerminalStatusValues(lro.U, statusValues, updateStatusValues)
+		setTerminalStatusValues(lro.P, statusValues, updateStatusValues)
+		setTerminalStatusValues(lro.D, statusValues, deleteStatusValues)
+
+		o.longRunningOperationsMap[tok] = lro
+	}
+}
+
+// getLongRunningOperation returns the description of the operation
+// if it is async, or nil otherwise.
+func (o *OpenAPIContext) getLongRunningOperation(desc *OperationDescriptor, hasReadEndpoint bool) *LongRunningOperation {
+	if desc == nil {
+		return nil
+	}
+
+	op := o.Doc.Paths.Find(desc.Path).GetOperation(desc.Method)
+
+	isLRO, _ := op.Extensions[ExtMSLongRunningOperation].(bool)
+	var responses []*openapi3.ResponseRef
+	if accepted := op.Responses.Status(202); accepted != nil {
+		isLRO = true
+		responses = append(responses, accepted)
+	}
+	if !isLRO {
+		return nil
+	}
+
+	// AutoRest specs may declare the polling header
+	// on any of the success responses.
+	for _, code := range desc.SuccessStatusCodes {
+		if r := op.Responses.Value(code); r != nil && code != "202" {
+			responses = append(responses, r)
+		}
+	}
+
+	if source, ok := getFinalStateViaHeader(op); ok && hasResponseHeader(responses, pollingHeaderNames[source]) {
+		return &LongRunningOperation{PollingURLSource: source}
+	}
+
+	for _, source := range pollingHeaders {
+		if hasResponseHeader(responses, pollingHeaderNames[source]) {
+			return &LongRunningOperation{PollingURLSource: source}
+		}
+	}
+
+	if !hasReadEndpoint {
+		o.diagnostics.warnf(CodeUnsupportedLongRunningOperation, operationPointer(desc.Path, desc.Method), "", "operation is async but has no polling URL header and the resource has no read endpoint")
+		return nil
+	}
+
+	return &LongRunningOperation{PollingURLSource: PollingURLSourceReadEndpoint}
+}
+
+// getFinalStateViaHeader returns the polling header named by the
+// `final-state-via` option of the ExtMSLongRunningOperationOptions
+// extension, if any.
+func getFinalStateViaHeader(op *openapi3.Operation) (PollingURLSource, bool) {
+	opts, ok := op.Extensions[ExtMSLongRunningOperationOptions].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	finalStateVia, _ := opts["final-state-via"].(string)
+	source, ok := finalStateViaHeaders[strings.ToLower(finalStateVia)]
+	return source, ok
+}
+
+// hasResponseHeader returns true if any of the responses has
+// the header. Header names are compared case-insensitively.
+func hasResponseHeader(responses []*openapi3.ResponseRef, header string) bool {
+	for _, r := range responses {
+		if r == nil || r.Value == nil {
+			continue
+		}
+
+		for name := range r.Value.Headers {
+			if strings.EqualFold(name, header) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getStatusProperty returns the path of the status property in the
+// output properties of a resource, and its enum values. Returns an
+// empty path if there isn't a status property with at least one
+// value that means a create succeeded.
+func (o *OpenAPIContext) getStatusProperty(props map[string]pschema.PropertySpec) (string, []string) {
+	for _, name := range statusPropertyNames {
+		prop, ok := props[name]
+		if !ok {
+			continue
+		}
+
+		values := o.getStatusValues(prop.TypeSpec)
+		if slices.ContainsFunc(values, func(v string) bool {
+			return createStatusValues.Has(strings.ToLower(v))
+		}) {
+			return name, values
+		}
+	}
+
+	for _, parent := range statusPropertyParents {
+		prop, ok := props[parent]
+		if !ok || !strings.HasPrefix(prop.Ref, typesSchemaRefPrefix) {
+			continue
+		}
+
+		objectType, ok := o.Pkg.Types[strings.TrimPrefix(prop.Ref, typesSchemaRefPrefix)]
+		if !ok {
+			continue
+		}
+
+		if name, values := o.getStatusProperty(objectType.Properties); name != "" {
+			return parent + "." + name, values
+		}
+	}
+
+	return "", nil
+}
+
+// getStatusValues returns the string enum values of a status
+// property type.
+func (o *OpenAPIContext) getStatusValues(typeSpec pschema.TypeSpec) []string {
+	if !strings.HasPrefix(typeSpec.Ref, typesSchemaRefPrefix) {
+		return nil
+	}
+
+	enumType, ok := o.Pkg.Types[strings.TrimPrefix(typeSpec.Ref, typesSchemaRefPrefix)]
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, e := range enumType.Enum {
+		if v, ok := e.Value.(string); ok {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// setTerminalStatusValues sets the status values that are known to
+// mean that the operation succeeded or failed.
+func setTerminalStatusValues(lro *LongRunningOperation, values []string, successValues codegen.StringSet) {
+	if lro == nil {
+		return
+	}
+
+	for _, v := range values {
+		if successValues.Has(strings.ToLower(v)) {
+			lro.SuccessValues = append(lro.SuccessValues, v)
+		} else if failureStatusValues.Has(strings.ToLower(v)) {
+			lro.FailureValues = append(lro.FailureValues, v)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLongRunningOperations tests that async operations are detected
+// and that the metadata describes how to wait for them.
+func TestLongRunningOperations(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "long_running_operations_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	t.Run("AcceptedWithHeaders", func(t *testing.T) {
+		assert.Equal(t, &LongRunningOperations{
+			C: &LongRunningOperation{
+				PollingURLSource: PollingURLSourceLocationHeader,
+				SuccessValues:    []string{"active"},
+				FailureValues:    []string{"failed"},
+			},
+			// A vault that is active hasn't been deleted yet.
+			D: &LongRunningOperation{
+				PollingURLSource: PollingURLSourceOperationLocationHeader,
+				SuccessValues:    []string{"deleted"},
+				FailureValues:    []string{"failed"},
+			},
+			StatusProperty: "status",
+		}, metadata.LongRunningOperationsMap["fake-package:vaults/v2:Vault"])
+	})
+
+	t.Run("AutoRestExtension", func(t *testing.T) {
+		lro, ok := metadata.LongRunningOperationsMap["fake-package:clusters/v2:Cluster"]
+		assert.Truef(t, ok, "Expected to find long-running operations for Cluster: %v", metadata.LongRunningOperationsMap)
+		if !ok {
+			return
+		}
+
+		assert.Equal(t, &LongRunningOperation{
+			PollingURLSource: PollingURLSourceAzureAsyncOperationHeader,
+			SuccessValues:    []string{"Succeeded"},
+			FailureValues:    []string{"Failed", "Canceled"},
+		}, lro.C)
+		assert.Equal(t, "properties.provisioningState", lro.StatusProperty)
+	})
+
+	t.Run("StatusProperty", func(t *testing.T) {
+		assert.Equal(t, &LongRunningOperations{
+			C: &LongRunningOperation{
+				PollingURLSource: PollingURLSourceReadEndpoint,
+				SuccessValues:    []string{"READY"},
+				FailureValues:    []string{"ERROR"},
+			},
+			StatusProperty: "state",
+		}, metadata.LongRunningOperationsMap["fake-package:queues/v2:Queue"])
+	})
+
+	t.Run("Synchronous", func(t *testing.T) {
+		assert.NotContains(t, metadata.LongRunningOperationsMap, "fake-package:notes/v2:Note")
+	})
+}
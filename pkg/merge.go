@@ -0,0 +1,182 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/cloudy-sky-software/pulschema/pkg/exclusions"
+)
+
+// OpenAPIDocument is one of the OpenAPI specs converted into a single
+// package by GatherResourcesFromAPIs, with the options that only apply
+// to it.
+type OpenAPIDocument struct {
+	// Name identifies the doc in the diagnostics and in the identities
+	// of its operations in the NamingLock, e.g. the name of the spec
+	// file. It is optional if only one of the docs has no name.
+	Name string
+	// Doc is the parsed, validated OpenAPI spec.
+	Doc *openapi3.T
+	// ModulePrefix is prepended to the modules of the resources,
+	// functions and types of the doc, e.g. the prefix `billing` puts
+	// the resources of the path `/v2/invoices` in the module
+	// `billing/invoices`. It keeps the tokens of docs that have
+	// similarly named paths or schemas from colliding.
+	ModulePrefix string
+	// Exclusions corresponds to OpenAPIContext.Exclusions.
+	Exclusions []exclusions.Exclusion
+	// ExcludedPaths corresponds to OpenAPIContext.ExcludedPaths.
+	// DEPRECATED: Use Exclusions.
+	ExcludedPaths []string
+}
+
+// GatherResourcesFromAPIs converts several OpenAPI docs into the single
+// package Pkg, for APIs that are split into a spec per service. Each doc
+// is converted with the options of the context and its own module prefix
+// and exclusions, and the returned metadata has the maps of all of them.
+// The Doc, Exclusions and ExcludedPaths of the context are not used.
+//
+// A token generated by more than one doc is reported as an error if the
+// docs generate different specs for it. The NamingLock, TokenHistory,
+// PreviousPackage and DeduplicateTypes apply to the merged package.
+//
+// Like GatherResourcesFromAPI, problems found in the docs are collected as
+// diagnostics. The pointers of the diagnostics are prefixed with the name
+// of the doc, e.g. `compute.yml#/paths/~1v2~1droplets/post`.
+func (o *OpenAPIContext) GatherResourcesFromAPIs(docs []OpenAPIDocument, csharpNamespaces map[string]string) (*ProviderMetadata, error) {
+	o.resetState()
+
+	sources := make(map[string]string)
+	for i, doc := range docs {
+		if doc.Doc == nil {
+			return nil, errors.Errorf("docs[%d] has no OpenAPI doc", i)
+		}
+		name := doc.Name
+		if name == "" {
+			name = fmt.Sprintf("docs[%d]", i)
+		}
+
+		docPkg := *o.Pkg
+		docPkg.Types = make(map[string]pschema.ComplexTypeSpec)
+		docPkg.Resources = make(map[string]pschema.ResourceSpec)
+		docPkg.Functions = make(map[string]pschema.FunctionSpec)
+
+		docCtx := *o
+		docCtx.Doc = *doc.Doc
+		docCtx.Pkg = &docPkg
+		docCtx.Exclusions = doc.Exclusions
+		docCtx.ExcludedPaths = doc.ExcludedPaths
+		docCtx.modulePrefix = doc.ModulePrefix
+		docCtx.NamingLock = nil
+		docCtx.TokenHistory = nil
+		docCtx.PreviousPackage = nil
+		docCtx.PreviousMetadata = nil
+		docCtx.DeduplicateTypes = false
+
+		_, _, err := docCtx.GatherResourcesFromAPI(csharpNamespaces)
+		var diags Diagnostics
+		if err != nil && !errors.As(err, &diags) {
+			return nil, errors.Wrapf(err, "converting %s", name)
+		}
+
+		for _, diag := range docCtx.Diagnostics() {
+			if doc.Name != "" && diag.Pointer != "" {
+				diag.Pointer = doc.Name + "#" + diag.Pointer
+			}
+			o.diagnostics.diags = append(o.diagnostics.diags, diag)
+		}
+
+		o.Pkg.Config = docPkg.Config
+		o.Pkg.Provider = docPkg.Provider
+		mergeTokens(o, "type", name, sources, o.Pkg.Types, docPkg.Types)
+		mergeTokens(o, "resource", name, sources, o.Pkg.Resources, docPkg.Resources)
+		mergeTokens(o, "function", name, sources, o.Pkg.Functions, docPkg.Functions)
+		o.mergeState(&docCtx, doc.Name)
+	}
+
+	o.applyNamingLock(csharpNamespaces)
+	o.gatherResourceAliases()
+	o.deduplicateTypes(csharpNamespaces)
+
+	if o.diagnostics.diags.HasErrors() {
+		return nil, o.diagnostics.diags
+	}
+
+	return o.newProviderMetadata(), nil
+}
+
+// mergeTokens copies the specs of a doc to the package and reports the
+// tokens that another doc generated with a different spec. sources is
+// a map of the tokens to the name of the doc that generated them.
+func mergeTokens[T any](o *OpenAPIContext, kind, docName string, sources map[string]string, dst, src map[string]T) {
+	for _, tok := range slices.Sorted(maps.Keys(src)) {
+		if existing, ok := dst[tok]; ok {
+			if !sameSpec(existing, src[tok]) {
+				o.diagnostics.errorf(CodeTokenCollision, "", tok, "the %s is generated by both %s and %s with different specs; use a different module prefix for one of them", kind, sources[tok], docName)
+			}
+			continue
+		}
+
+		dst[tok] = src[tok]
+		sources[tok] = docName
+	}
+}
+
+// sameSpec returns true if the two specs have the same JSON form.
+func sameSpec(a, b any) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aJSON) == string(bJSON)
+}
+
+// mergeState copies the maps populated by the conversion of a doc to
+// the maps of the merged package. The entries of the tokens that were
+// already generated by an earlier doc are kept.
+func (o *OpenAPIContext) mergeState(src *OpenAPIContext, docName string) {
+	mergeMap(o.resourceCRUDMap, src.resourceCRUDMap)
+	mergeMap(o.autoNameMap, src.autoNameMap)
+	mergeMap(o.autoNameConstraintsMap, src.autoNameConstraintsMap)
+	mergeMap(o.sdkToAPINameMap, src.sdkToAPINameMap)
+	mergeMap(o.apiToSDKNameMap, src.apiToSDKNameMap)
+	mergeMap(o.pathParamNameMap, src.pathParamNameMap)
+	mergeMap(o.patchRequestSchemas, src.patchRequestSchemas)
+	mergeMap(o.replaceOnChangesMap, src.replaceOnChangesMap)
+	mergeMap(o.patchablePropertiesMap, src.patchablePropertiesMap)
+	mergeMap(o.longRunningOperationsMap, src.longRunningOperationsMap)
+	mergeMap(o.queryParamsMap, src.queryParamsMap)
+	mergeMap(o.headerParamsMap, src.headerParamsMap)
+	mergeMap(o.providerHeaderParamsMap, src.providerHeaderParamsMap)
+	mergeMap(o.paginationMap, src.paginationMap)
+	mergeMap(o.writeOnlyPropertiesMap, src.writeOnlyPropertiesMap)
+	mergeMap(o.binaryPropertiesMap, src.binaryPropertiesMap)
+	mergeMap(o.validationConstraintsMap, src.validationConstraintsMap)
+
+	prefix := ""
+	if docName != "" {
+		prefix = docName + " "
+	}
+	for identity, tok := range src.resourceIdentities {
+		o.resourceIdentities[prefix+identity] = tok
+	}
+	for identity, tok := range src.functionIdentities {
+		o.functionIdentities[prefix+identity] = tok
+	}
+}
+
+// mergeMap copies the entries of src whose key is not in dst.
+func mergeMap[T any](dst, src map[string]T) {
+	for k, v := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+}
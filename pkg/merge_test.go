@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	"github.com/cloudy-sky-software/pulschema/pkg/exclusions"
+)
+
+// newMergeTestContext returns an OpenAPIContext that converts the docs
+// of the merge tests into a new package, and the docs with the given
+// module prefixes.
+func newMergeTestContext(t *testing.T, computePrefix, storagePrefix string) (*OpenAPIContext, []OpenAPIDocument) {
+	loadDoc := func(name string) *openapi3.T {
+		doc, err := openapi3.NewLoader().LoadFromFile(filepath.Join("testdata", name))
+		require.NoError(t, err)
+		return doc
+	}
+
+	openAPICtx := &OpenAPIContext{
+		Pkg: &pschema.PackageSpec{
+			Name:      packageName,
+			Types:     map[string]pschema.ComplexTypeSpec{},
+			Resources: map[string]pschema.ResourceSpec{},
+			Functions: map[string]pschema.FunctionSpec{},
+			Language:  map[string]pschema.RawMessage{},
+		},
+	}
+
+	docs := []OpenAPIDocument{
+		{
+			Name:         "compute.yml",
+			Doc:          loadDoc("merge_compute_openapi.yml"),
+			ModulePrefix: computePrefix,
+		},
+		{
+			Name:         "storage.yml",
+			Doc:          loadDoc("merge_storage_openapi.yml"),
+			ModulePrefix: storagePrefix,
+			Exclusions:   []exclusions.Exclusion{{PathPattern: "/v2/sheds"}},
+		},
+	}
+
+	return openAPICtx, docs
+}
+
+func TestGatherResourcesFromAPIs(t *testing.T) {
+	openAPICtx, docs := newMergeTestContext(t, "compute", "storage")
+
+	csharpNamespaces := map[string]string{"": providerNamespace}
+	metadata, err := openAPICtx.GatherResourcesFromAPIs(docs, csharpNamespaces)
+	require.NoError(t, err)
+
+	computeCanoe := packageName + ":compute/canoes/v2:Canoe"
+	storageCanoe := packageName + ":storage/canoes/v2:Canoe"
+	tent := packageName + ":compute/tents/v2:Tent"
+	assert.ElementsMatch(t, []string{computeCanoe, storageCanoe, tent}, slices.Collect(maps.Keys(openAPICtx.Pkg.Resources)))
+	assert.Contains(t, openAPICtx.Pkg.Resources[computeCanoe].InputProperties, "length")
+	assert.Contains(t, openAPICtx.Pkg.Resources[storageCanoe].InputProperties, "paddles")
+	assert.Equal(t, "ComputeCanoesV2", csharpNamespaces["compute/canoes/v2"])
+
+	for _, tok := range []string{computeCanoe, storageCanoe, tent} {
+		if assert.Contains(t, metadata.ResourceCRUDMap, tok) {
+			assert.NotNil(t, metadata.ResourceCRUDMap[tok].C)
+		}
+	}
+
+	lock := openAPICtx.GeneratedNamingLock()
+	assert.Equal(t, computeCanoe, lock.Resources["compute.yml POST /v2/canoes"])
+	assert.Equal(t, storageCanoe, lock.Resources["storage.yml POST /v2/canoes"])
+}
+
+func TestGatherResourcesFromAPIsTokenCollision(t *testing.T) {
+	openAPICtx, docs := newMergeTestContext(t, "", "")
+
+	_, err := openAPICtx.GatherResourcesFromAPIs(docs, map[string]string{"": providerNamespace})
+	require.Error(t, err)
+
+	var collisions []string
+	for _, diag := range openAPICtx.Diagnostics() {
+		if diag.Code == CodeTokenCollision {
+			collisions = append(collisions, diag.Token)
+		}
+	}
+	assert.Equal(t, []string{packageName + ":canoes/v2:SeatProperties", packageName + ":canoes/v2:Canoe"}, collisions)
+	assert.Contains(t, openAPICtx.Pkg.Resources, packageName+":tents/v2:Tent")
+}
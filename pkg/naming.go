@@ -72,14 +72,15 @@ func toCamelInitCase(s string, initCase bool) string {
 	return n
 }
 
-func addNameOverride(key, value string, m map[string]string) {
+func addNameOverride(key, value string, m map[string]string) error {
 	if v, ok := m[key]; ok && value != v {
-		panic(fmt.Errorf(
+		return fmt.Errorf(
 			"mapping for %s already exists and has a value %s but a new mapping with value %s was requested",
-			key, v, value))
+			key, v, value)
 	}
 
 	m[key] = value
+	return nil
 }
 
 // getSingularNameForResource returns a singular version of a resource name,
@@ -99,3 +100,11 @@ func getSingularNameForResource(resourceName string, allowedPluralNames []string
 
 	return resourceName
 }
+
+// addNameOverride adds a name mapping to m and reports a diagnostic,
+// instead of overwriting it, if a different mapping already exists.
+func (ctx *resourceContext) addNameOverride(key, value string, m map[string]string) {
+	if err := addNameOverride(key, value, m); err != nil {
+		ctx.diagnostics.errorf(CodeNameOverrideConflict, ctx.pointer, "", "%v", err)
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/oasdiff/yaml"
+	"github.com/pkg/errors"
+)
+
+// NamingLockVersionV1 is the first version of the naming lock format.
+const NamingLockVersionV1 = "v1"
+
+// NamingLock maps the identity of the operations, and of the schemas of
+// discriminated operations, to the tokens generated for them by an earlier
+// conversion. It keeps the tokens of resources and functions stable when
+// operationIds or the naming heuristics change, since a renamed resource
+// token makes Pulumi replace the existing resources.
+//
+// The identity of a resource is the method and path of its create
+// operation, e.g. `POST /v2/droplets`. The identity of a function is
+// the method and path of its GET operation. The ref of the discriminated
+// schema is appended to the identity of resources and functions generated
+// for a discriminator mapping, e.g.
+// `POST /v2/droplets/{droplet_id}/actions#/components/schemas/droplet_action_resize`.
+//
+// The tokens of object and enum types are not locked. They don't affect
+// the state of the resources in users' stacks.
+type NamingLock struct {
+	// Version is the version of the lock format. Must be "v1".
+	Version string `json:"version"`
+	// Resources is a map of the resource identities to their tokens.
+	Resources map[string]string `json:"resources"`
+	// Functions is a map of the function identities to their tokens.
+	Functions map[string]string `json:"functions"`
+}
+
+// LoadNamingLock reads the naming lock file at path.
+func LoadNamingLock(path string) (*NamingLock, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading naming lock %s", path)
+	}
+
+	l, err := ParseNamingLock(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading naming lock %s", path)
+	}
+
+	return l, nil
+}
+
+// ParseNamingLock parses and validates a YAML or JSON naming lock.
+func ParseNamingLock(b []byte) (*NamingLock, error) {
+	jsonBytes, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing naming lock")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.DisallowUnknownFields()
+
+	var l NamingLock
+	if err := decoder.Decode(&l); err != nil {
+		return nil, errors.Wrap(err, "decoding naming lock")
+	}
+
+	var problems []string
+	if l.Version != NamingLockVersionV1 {
+		problems = append(problems, fmt.Sprintf("unsupported version %q (must be %s)", l.Version, NamingLockVersionV1))
+	}
+	for _, identities := range []struct {
+		kind   string
+		tokens map[string]string
+	}{{"resources", l.Resources}, {"functions", l.Functions}} {
+		for _, identity := range slices.Sorted(maps.Keys(identities.tokens)) {
+			if tok := identities.tokens[identity]; !isTypeToken(tok) {
+				problems = append(problems, fmt.Sprintf("%s[%q] must be a token of the form pkg:module:name but got %q", identities.kind, identity, tok))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.Errorf("invalid naming lock: %s", strings.Join(problems, "; "))
+	}
+
+	return &l, nil
+}
+
+// NewNamingLock returns an empty naming lock.
+func NewNamingLock() *NamingLock {
+	return &NamingLock{
+		Version:   NamingLockVersionV1,
+		Resources: make(map[string]string),
+		Functions: make(map[string]string),
+	}
+}
+
+// GeneratedNamingLock returns the naming lock for the resources and
+// functions generated by the last call to GatherResourcesFromAPI. It
+// has the locked tokens that were reused, the tokens of the new
+// resources and functions, and none of the operations that were
+// removed from the spec.
+func (o *OpenAPIContext) GeneratedNamingLock() *NamingLock {
+	l := NewNamingLock()
+	maps.Copy(l.Resources, o.resourceIdentities)
+	maps.Copy(l.Functions, o.functionIdentities)
+	return l
+}
+
+// getOperationIdentity returns the identity of an operation in a
+// NamingLock, with the ref of the discriminated schema, if any.
+func getOperationIdentity(method, apiPath, schemaRef string) string {
+	return strings.ToUpper(method) + " " + apiPath + schemaRef
+}
+
+// applyNamingLock renames the resources and functions whose generated
+// token differs from the token in the NamingLock back to the locked
+// token, and reports the drift as warnings.
+func (o *OpenAPIContext) applyNamingLock(csharpNamespaces map[string]string) {
+	if o.NamingLock == nil {
+		return
+	}
+
+	apply := func(kind string, identities, locked map[string]string, exists func(string) bool) {
+		for _, identity := range slices.Sorted(maps.Keys(identities)) {
+			tok := identities[identity]
+			lockedTok, ok := locked[identity]
+			if !ok || lockedTok == tok {
+				continue
+			}
+
+			if exists(lockedTok) {
+				o.diagnostics.warnf(CodeTokenDrift, "", tok, "%s %s was locked to %s, which is already used by another %s; keeping the new token", kind, identity, lockedTok, kind)
+				continue
+			}
+
+			o.diagnostics.warnf(CodeTokenDrift, "", lockedTok, "%s %s now generates %s; using the locked token", kind, identity, tok)
+			o.renameToken(tok, lockedTok)
+			identities[identity] = lockedTok
+
+			module := strings.Split(lockedTok, ":")[1]
+			if _, ok := csharpNamespaces[module]; !ok {
+				csharpNamespaces[module] = moduleToPascalCase(module)
+			}
+		}
+
+		for _, identity := range slices.Sorted(maps.Keys(locked)) {
+			if _, ok := identities[identity]; !ok {
+				o.diagnostics.warnf(CodeTokenDrift, "", locked[identity], "%s %s no longer exists; removing it from the naming lock", kind, identity)
+			}
+		}
+	}
+
+	apply("resource", o.resourceIdentities, o.NamingLock.Resources, func(tok string) bool {
+		_, ok := o.Pkg.Resources[tok]
+		return ok
+	})
+	apply("function", o.functionIdentities, o.NamingLock.Functions, func(tok string) bool {
+		_, ok := o.Pkg.Functions[tok]
+		return ok
+	})
+}
+
+// renameToken renames a resource or function token in the package and
+// in the maps keyed by tokens.
+//
+// The other operations of a resource may still generate the locked
+// token, so the CRUD operations under both tokens are merged, and the
+// PATCH request schema of the locked token is kept.
+func (o *OpenAPIContext) renameToken(oldTok, newTok string) {
+	renameKey(o.Pkg.Resources, oldTok, newTok)
+	renameKey(o.Pkg.Functions, oldTok, newTok)
+	if crud, ok := o.resourceCRUDMap[oldTok]; ok {
+		if lockedCRUD, ok := o.resourceCRUDMap[newTok]; ok {
+			mergeCRUDOperations(lockedCRUD, crud)
+			delete(o.resourceCRUDMap, oldTok)
+		} else {
+			renameKey(o.resourceCRUDMap, oldTok, newTok)
+		}
+	}
+	if _, ok := o.patchRequestSchemas[newTok]; !ok {
+		renameKey(o.patchRequestSchemas, oldTok, newTok)
+	}
+	renameKey(o.autoNameMap, oldTok, newTok)
+	renameKey(o.autoNameConstraintsMap, oldTok, newTok)
+	renameKey(o.queryParamsMap, oldTok, newTok)
+	renameKey(o.headerParamsMap, oldTok, newTok)
+	renameKey(o.paginationMap, oldTok, newTok)
+	renameKey(o.writeOnlyPropertiesMap, oldTok, newTok)
+	renameKey(o.validationConstraintsMap, oldTok, newTok)
+	renameKey(o.replaceOnChangesMap, oldTok, newTok)
+	renameKey(o.patchablePropertiesMap, oldTok, newTok)
+	renameKey(o.longRunningOperationsMap, oldTok, newTok)
+	renameKey(o.binaryPropertiesMap, oldTok, newTok)
+}
+
+func renameKey[T any](m map[string]T, oldKey, newKey string) {
+	v, ok := m[oldKey]
+	if !ok {
+		return
+	}
+
+	delete(m, oldKey)
+	m[newKey] = v
+}
+
+// mergeCRUDOperations sets the operations of src that are not set in dst.
+func mergeCRUDOperations(dst, src *CRUDOperationsMap) {
+	if dst.C == nil {
+		dst.C, dst.Operations.C = src.C, src.Operations.C
+	}
+	if dst.R == nil {
+		dst.R, dst.Operations.R = src.R, src.Operations.R
+	}
+	if dst.U == nil {
+		dst.U, dst.Operations.U = src.U, src.Operations.U
+	}
+	if dst.D == nil {
+		dst.D, dst.Operations.D = src.D, src.Operations.D
+	}
+	if dst.P == nil {
+		dst.P, dst.Operations.P = src.P, src.Operations.P
+	}
+}
+
+// isTypeToken returns true if tok has the pkg:module:name form.
+func isTypeToken(tok string) bool {
+	parts := strings.Split(tok, ":")
+	return len(parts) == 3 && parts[0] != "" && parts[2] != ""
+}
@@ -0,0 +1,85 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamingLock(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "naming_lock_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+		NamingLock: &NamingLock{
+			Version: NamingLockVersionV1,
+			Resources: map[string]string{
+				"POST /v2/beacons": packageName + ":beacons/v2:Beacon",
+			},
+			Functions: map[string]string{
+				"GET /v2/beacons":             packageName + ":beacons/v2:listBeacons",
+				"GET /v2/beacons/{beacon_id}": packageName + ":beacons/v2:getLighthouse",
+				"GET /v2/lighthouses":         packageName + ":lighthouses/v2:listLighthouses",
+			},
+		},
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(map[string]string{"": providerNamespace})
+	assert.Nil(t, err)
+
+	beaconTok := packageName + ":beacons/v2:Beacon"
+	assert.Contains(t, testPulumiPkg.Resources, beaconTok)
+	assert.NotContains(t, testPulumiPkg.Resources, packageName+":beacons/v2:Signal")
+
+	// The create operation is merged with the read and delete
+	// operations, which still generate the locked token.
+	crud := metadata.ResourceCRUDMap[beaconTok]
+	if assert.NotNil(t, crud) {
+		assert.Equal(t, "/v2/beacons", *crud.C)
+		assert.Equal(t, "/v2/beacons/{beacon_id}", *crud.R)
+		assert.Equal(t, "/v2/beacons/{beacon_id}", *crud.D)
+	}
+	assert.NotContains(t, metadata.ResourceCRUDMap, packageName+":beacons/v2:Signal")
+
+	getTok := packageName + ":beacons/v2:getLighthouse"
+	assert.Contains(t, testPulumiPkg.Functions, getTok)
+	assert.NotContains(t, testPulumiPkg.Functions, packageName+":beacons/v2:getBeacon")
+	assert.Contains(t, metadata.ResourceCRUDMap, getTok)
+
+	var drift []string
+	for _, d := range openAPICtx.Diagnostics() {
+		if d.Code == CodeTokenDrift {
+			drift = append(drift, d.Message)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"resource POST /v2/beacons now generates " + packageName + ":beacons/v2:Signal; using the locked token",
+		"function GET /v2/beacons/{beacon_id} now generates " + packageName + ":beacons/v2:getBeacon; using the locked token",
+		"function GET /v2/lighthouses no longer exists; removing it from the naming lock",
+	}, drift)
+
+	lock := openAPICtx.GeneratedNamingLock()
+	assert.Equal(t, beaconTok, lock.Resources["POST /v2/beacons"])
+	assert.Equal(t, getTok, lock.Functions["GET /v2/beacons/{beacon_id}"])
+	assert.Equal(t, packageName+":beacons/v2:listBeacons", lock.Functions["GET /v2/beacons"])
+	assert.NotContains(t, lock.Functions, "GET /v2/lighthouses")
+}
+
+func TestParseNamingLock(t *testing.T) {
+	lock, err := ParseNamingLock([]byte(`
+version: v1
+resources:
+  POST /v2/beacons: fake-package:beacons/v2:Beacon
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, "fake-package:beacons/v2:Beacon", lock.Resources["POST /v2/beacons"])
+
+	_, err = ParseNamingLock([]byte(`
+version: v2
+functions:
+  GET /v2/beacons: listBeacons
+`))
+	assert.EqualError(t, err, `invalid naming lock: unsupported version "v2" (must be v1); functions["GET /v2/beacons"] must be a token of the form pkg:module:name but got "listBeacons"`)
+}
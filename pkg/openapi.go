@@ -20,8 +20,6 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/codegen"
 	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 
-	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
-
 	"github.com/cloudy-sky-software/pulschema/pkg/exclusions"
 )
 
@@ -30,7 +28,11 @@ const (
 	typesSchemaRefPrefix      = "#/types/"
 	jsonMimeType              = "application/json"
 	plainTextMimeType         = "text/plain"
+	multipartFormDataMimeType = "multipart/form-data"
+	octetStreamMimeType       = "application/octet-stream"
 	parameterLocationPath     = "path"
+	parameterLocationQuery    = "query"
+	parameterLocationHeader   = "header"
 	pathSeparator             = "/"
 
 	typeString     = "string"
@@ -93,6 +95,74 @@ type OpenAPIContext struct {
 	// be converted to their singular version.
 	AllowedPluralResources []string
 
+	// Strict promotes all warning diagnostics to errors.
+	Strict bool
+
+	// HeaderParams maps header param names to where their values
+	// come from. Header params that aren't in the map are mapped
+	// to the provider if every operation declares them, or to the
+	// inputs of the resources and functions otherwise.
+	HeaderParams map[string]HeaderParamTarget
+
+	// Pagination is a map of the operationId of list endpoints and
+	// their pagination scheme, for endpoints whose pagination isn't
+	// detected correctly. Use PaginationSchemeNone to record that
+	// an endpoint isn't paginated.
+	Pagination map[string]*Pagination
+
+	// SkipDeprecatedOperations removes the deprecated operations from
+	// the doc before the conversion, so that they don't become resources
+	// or functions. Otherwise, they are converted with a deprecation
+	// message.
+	SkipDeprecatedOperations bool
+
+	// AutoNameProperties is a slice of the API names of the properties
+	// that can be auto-named by the provider, in order of preference.
+	// The first one that is a required property of a resource's request
+	// body is auto-named. Defaults to `name`. The ExtAutoName extension
+	// takes precedence over it.
+	AutoNameProperties []string
+
+	// DeduplicateTypes merges the object and enum types with the same
+	// spec into a single type, e.g. the types of identical inline
+	// schemas, or of a component schema that is referenced in several
+	// modules. It makes the SDKs smaller but renames the merged types.
+	DeduplicateTypes bool
+
+	// SharedTypesModule is the module of the merged types that are
+	// identical in several modules. If not set, they are merged into
+	// one of the modules.
+	SharedTypesModule string
+
+	// TypeCollisionStrategy is how an object type is named when another
+	// type with a different spec already has its token. Defaults to
+	// TypeCollisionStrategyPrefix.
+	TypeCollisionStrategy TypeCollisionStrategy
+
+	// NamingLock holds the tokens generated for the operations by an
+	// earlier conversion. If set, the locked tokens are reused for the
+	// resources and functions whose generated token changed. Use
+	// GeneratedNamingLock to get the lock to save for the next run.
+	NamingLock *NamingLock
+
+	// TokenHistory is a map of resource type tokens to the tokens the
+	// resources had in earlier versions of the package. The earlier
+	// tokens are added to the aliases of the resources so that Pulumi
+	// doesn't replace the existing resources.
+	TokenHistory map[string][]string
+
+	// PreviousPackage is the package generated by an earlier version,
+	// and PreviousMetadata is its metadata, which is optional. The
+	// tokens of the previous resources that moved to another module,
+	// or were renamed but kept the same create endpoint, are added to
+	// the aliases of the new resources.
+	PreviousPackage  *pschema.PackageSpec
+	PreviousMetadata *ProviderMetadata
+
+	// modulePrefix is prepended to the modules of the
+	// resources, functions and types of the doc. It is set
+	// for the documents converted by GatherResourcesFromAPIs.
+	modulePrefix string
 	// resourceCRUDMap is a map of the Pulumi resource type
 	// token to its CRUD endpoints.
 	resourceCRUDMap map[string]*CRUDOperationsMap
@@ -101,8 +171,16 @@ type OpenAPIContext struct {
 	exclusionEvaluator *exclusions.ExclusionEvaluator
 	// autoNameMap is a map of the resource type token
 	// and the property that can be auto-named.
-	autoNameMap  map[string]string
-	visitedTypes codegen.StringSet
+	autoNameMap map[string]string
+	// autoNameConstraintsMap is a map of the resource type
+	// token and the constraints on the values of its
+	// auto-named property.
+	autoNameConstraintsMap map[string]*AutoNameConstraints
+	visitedTypes           codegen.StringSet
+	// renamedTypes is a map of the tokens derived from the
+	// names of component schemas to the tokens that their
+	// types were added under because of a collision.
+	renamedTypes map[string]string
 	// sdkToAPINameMap is a map of Pulumi type tokens whose
 	// property names have been overridden to be camelCase
 	// instead of the name used by the provider API.
@@ -118,6 +196,54 @@ type OpenAPIContext struct {
 	// param in the inputs map.
 	pathParamNameMap       map[string]string
 	allowedPluralResources []string
+	// patchRequestSchemas is a map of the resource type token
+	// and the request body schema of its PATCH endpoint.
+	patchRequestSchemas map[string]*openapi3.Schema
+	// replaceOnChangesMap is a map of the resource type token
+	// and the paths of the input properties that force
+	// a replacement of the resource.
+	replaceOnChangesMap map[string][]string
+	// patchablePropertiesMap is a map of the resource type
+	// token and the input properties that can be updated
+	// using its PATCH endpoint.
+	patchablePropertiesMap map[string][]string
+	// longRunningOperationsMap is a map of the resource type
+	// token and the description of its async operations.
+	longRunningOperationsMap map[string]*LongRunningOperations
+	// queryParamsMap is a map of the function type token and
+	// a map of its input names to their query param names.
+	queryParamsMap map[string]map[string]string
+	// headerParamsMap is a map of the resource or function
+	// type token and a map of its input names to their
+	// header param names.
+	headerParamsMap map[string]map[string]string
+	// providerHeaderParamsMap is a map of the provider
+	// input names to their header param names.
+	providerHeaderParamsMap map[string]string
+	// providerHeaderParams is the set of the lowercase
+	// names of the header params declared by every
+	// operation.
+	providerHeaderParams codegen.StringSet
+	// paginationMap is a map of the list function type
+	// token and the pagination scheme of its endpoint.
+	paginationMap map[string]*Pagination
+	// writeOnlyPropertiesMap is a map of the resource type
+	// token and the paths of its writeOnly input properties.
+	writeOnlyPropertiesMap map[string][]string
+	// binaryPropertiesMap is a map of the resource type token
+	// and how its asset and archive inputs are sent.
+	binaryPropertiesMap map[string]map[string]*BinaryEncoding
+	// validationConstraintsMap is a map of the resource type token
+	// and the validation constraints of its input properties.
+	validationConstraintsMap map[string]map[string]*ValidationConstraints
+	// resourceIdentities and functionIdentities are maps of the
+	// identities of the operations in a NamingLock to the resource
+	// and function tokens generated for them.
+	resourceIdentities map[string]string
+	functionIdentities map[string]string
+	// diagnostics collects the problems found during
+	// the conversion.
+	diagnostics *diagnosticsCollector
 }
 
 type duplicateEnumError struct {
@@ -139,7 +265,13 @@ func (d *duplicateEnumError) Error() string {
 //   - The "update" operation (denoted by a Patch request) determines the schema
 //     for resource updates. The Patch request schema is used to determine
 //     which properties can be patched when changes are detected in Diff() vs.
-//     which ones will force a resource replacement.
+//     which ones will force a resource replacement. Resources that can't be
+//     updated at all are always replaced.
+//
+// Problems found in the spec don't stop the conversion. Instead, they are
+// collected as diagnostics, which can be retrieved using Diagnostics().
+// If any of them is an error, the returned error is of type Diagnostics
+// and contains all of the diagnostics.
 func (o *OpenAPIContext) GatherResourcesFromAPI(csharpNamespaces map[string]string) (*ProviderMetadata, openapi3.T, error) {
 	evaluator, err := exclusions.NewExclusionEvaluator(o.Exclusions, o.ExcludedPaths)
 	if err != nil {
@@ -151,15 +283,20 @@ func (o *OpenAPIContext) GatherResourcesFromAPI(csharpNamespaces map[string]stri
 		glog.V(1).Infof("Loaded %d exclusion rules", evaluator.Count())
 	}
 
-	o.resourceCRUDMap = make(map[string]*CRUDOperationsMap)
-	o.autoNameMap = make(map[string]string)
-	o.visitedTypes = codegen.NewStringSet()
-	o.sdkToAPINameMap = make(map[string]string)
-	o.apiToSDKNameMap = make(map[string]string)
-	o.pathParamNameMap = make(map[string]string)
+	if err := o.validatePagination(); err != nil {
+		return nil, o.Doc, err
+	}
+
+	o.resetState()
 
 	o.allowedPluralResources = append(o.AllowedPluralResources, defaultAllowedPluralResourceNames...)
 
+	if o.SkipDeprecatedOperations {
+		o.removeDeprecatedOperations()
+	}
+
+	o.gatherProviderHeaderParams()
+
 	for _, path := range o.Doc.Paths.InMatchingOrder() {
 		pathItem := o.Doc.Paths.Find(path)
 		if pathItem == nil {
@@ -170,7 +307,7 @@ func (o *OpenAPIContext) GatherResourcesFromAPI(csharpNamespaces map[string]stri
 		// in the crudMap.
 		currentPath := path
 		parentPath := getParentPath(currentPath)
-		module := getModuleFromPath(currentPath, o.UseParentResourceAsModule)
+		module := o.prefixModule(getModuleFromPath(currentPath, o.UseParentResourceAsModule))
 
 		// Check each HTTP method for this path
 		methods := []string{}
@@ -201,254 +338,328 @@ func (o *OpenAPIContext) GatherResourcesFromAPI(csharpNamespaces map[string]stri
 			csharpNamespaces[module] = moduleToPascalCase(module)
 		}
 
-		glog.V(3).Infof("Processing path %s as %s\n", path, currentPath)
+		// getOpExtensions returns the extensions of the operation for
+		// the method. They are resolved once per operation, so that an
+		// invalid value is only reported once.
+		opExtensions := make(map[string]*operationExtensions)
+		getOpExtensions := func(method string) *operationExtensions {
+			if ext, ok := opExtensions[method]; ok {
+				return ext
+			}
+			ext := o.getOperationExtensions(currentPath, method, pathItem.GetOperation(method))
+			opExtensions[method] = ext
+			return ext
+		}
+
+		// getOperationModule returns the module pinned on the operation
+		// using the ExtModule extension, if any, or the module derived
+		// from the path.
+		getOperationModule := func(method string) string {
+			m := getOpExtensions(method).module
+			if m == "" {
+				return module
+			}
+			m = o.prefixModule(m)
 
-		if pathItem.Get != nil {
-			if o.exclusionEvaluator.ShouldExclude("GET", path) {
-				glog.V(2).Infof("Excluding GET %s", path)
-				continue
+			if _, ok := csharpNamespaces[m]; !ok {
+				csharpNamespaces[m] = moduleToPascalCase(m)
 			}
+			return m
+		}
 
-			contract.Assertf(pathItem.Get.OperationID != "", "operationId is missing for path GET %s", currentPath)
+		glog.V(3).Infof("Processing path %s as %s\n", path, currentPath)
 
-			glog.V(3).Infof("GET: Parent path for %s is %s\n", currentPath, parentPath)
+		// The operations are converted in closures so that a problem
+		// with one of them only skips that operation, and the problems
+		// of the other operations of the path are reported too.
+		if pathItem.Get != nil {
+			func() {
+				if o.exclusionEvaluator.ShouldExclude("GET", path) {
+					glog.V(2).Infof("Excluding GET %s", path)
+					return
+				}
 
-			// GET endpoints may not have a response body at all.
-			// For example, ad-hoc actions like restarting a VM or
-			// initiating a backup may return 204 No Content.
-			var respType *openapi3.MediaType
-			statusOkResp := pathItem.Get.Responses.Status(200)
-			if statusOkResp != nil && statusOkResp.Value != nil && statusOkResp.Value.Content != nil {
-				respType = statusOkResp.Value.Content.Get(jsonMimeType)
-				// If the JSON mime type is not defined, try to check for the text/plain
-				// response type.
-				if respType == nil || respType.Schema == nil || respType.Schema.Value == nil {
-					respType = statusOkResp.Value.Content.Get(plainTextMimeType)
+				if pathItem.Get.OperationID == "" {
+					o.diagnostics.errorf(CodeMissingOperationID, operationPointer(currentPath, http.MethodGet), "", "operationId is missing for path GET %s", currentPath)
+					return
 				}
-			}
-			if respType == nil || respType.Schema == nil || respType.Schema.Value == nil {
-				// Create an empty response type.
-				respType = openapi3.NewMediaType().WithSchema(defaultEmptySchemaDoNotMutate)
-			}
 
-			setReadOperationMapping := func(tok string) {
-				if existing, ok := o.resourceCRUDMap[tok]; ok {
-					existing.R = &currentPath
-				} else {
-					o.resourceCRUDMap[tok] = &CRUDOperationsMap{
-						R: &currentPath,
+				getModule := getOperationModule(http.MethodGet)
+
+				glog.V(3).Infof("GET: Parent path for %s is %s\n", currentPath, parentPath)
+
+				// GET endpoints may not have a response body at all.
+				// For example, ad-hoc actions like restarting a VM or
+				// initiating a backup may return 204 No Content.
+				var respType *openapi3.MediaType
+				statusOkResp := pathItem.Get.Responses.Status(200)
+				if statusOkResp != nil && statusOkResp.Value != nil && statusOkResp.Value.Content != nil {
+					respType = statusOkResp.Value.Content.Get(jsonMimeType)
+					// If the JSON mime type is not defined, try to check for the text/plain
+					// response type.
+					if respType == nil || respType.Schema == nil || respType.Schema.Value == nil {
+						respType = statusOkResp.Value.Content.Get(plainTextMimeType)
 					}
 				}
-			}
+				if respType == nil || respType.Schema == nil || respType.Schema.Value == nil {
+					// Create an empty response type.
+					respType = openapi3.NewMediaType().WithSchema(defaultEmptySchemaDoNotMutate)
+				}
 
-			resourceType := respType.Schema.Value
+				setReadOperationMapping := func(tok string) {
+					if _, ok := o.resourceCRUDMap[tok]; !ok {
+						o.resourceCRUDMap[tok] = &CRUDOperationsMap{}
+					}
+					o.resourceCRUDMap[tok].R = &currentPath
+					o.resourceCRUDMap[tok].Operations.R = o.newOperationDescriptor(currentPath, http.MethodGet)
+				}
 
-			// Use the type and operationID as a hint to determine if this GET endpoint returns a single resource
-			// or a list of resources.
-			if !resourceType.Type.Is(openapi3.TypeArray) && !strings.Contains(strings.ToLower(pathItem.Get.OperationID), "list") {
-				// If there is a discriminator then we should set this operation
-				// as the read endpoint for each of the types in the mapping.
-				if resourceType.Discriminator != nil {
-					for _, ref := range resourceType.Discriminator.Mapping {
-						schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
-						dResource := o.Doc.Components.Schemas[schemaName]
-						title := getResourceTitleFromRequestSchema(schemaName, dResource)
-						typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, title)
+				resourceType := respType.Schema.Value
+
+				// Use the type and operationID as a hint to determine if this GET endpoint returns a single resource
+				// or a list of resources.
+				if !resourceType.Type.Is(openapi3.TypeArray) && !strings.Contains(strings.ToLower(pathItem.Get.OperationID), "list") {
+					// If there is a discriminator then we should set this operation
+					// as the read endpoint for each of the types in the mapping.
+					if resourceType.Discriminator != nil {
+						o.warnIgnoredResourceNameExtension(currentPath, http.MethodGet, getOpExtensions(http.MethodGet))
+						for _, value := range slices.Sorted(maps.Keys(resourceType.Discriminator.Mapping)) {
+							ref := resourceType.Discriminator.Mapping[value]
+							schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
+							dResource := o.Doc.Components.Schemas[schemaName]
+							title := getResourceTitleFromRequestSchema(schemaName, dResource)
+							typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, getModule, title)
+							setReadOperationMapping(typeToken)
+
+							funcName := "get" + dResource.Value.Title
+							funcTypeToken := o.Pkg.Name + ":" + getModule + ":" + funcName
+							getterFuncSpec, err := o.genGetFunc(currentPath, *pathItem, *dResource, getModule, funcName)
+							if err != nil {
+								o.diagnostics.errorf(CodeUnsupportedSchema, operationPointer(currentPath, http.MethodGet), funcTypeToken, "generating get function: %v", err)
+								continue
+							}
+							o.Pkg.Functions[funcTypeToken] = *getterFuncSpec
+							setReadOperationMapping(funcTypeToken)
+							o.functionIdentities[getOperationIdentity(http.MethodGet, currentPath, ref.Ref)] = funcTypeToken
+						}
+					} else {
+						resourceName := o.getResourceName(getOpExtensions(http.MethodGet), http.MethodGet, pathItem.Get, true)
+
+						// The resource needs to be read from the cloud provider API,
+						// so we should map this "read" endpoint for this resource.
+						// This is in addition to separately adding the "get" function
+						// too.
+						typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, getModule, resourceName)
 						setReadOperationMapping(typeToken)
 
-						funcName := "get" + dResource.Value.Title
-						funcTypeToken := o.Pkg.Name + ":" + module + ":" + funcName
-						getterFuncSpec := o.genGetFunc(*pathItem, *dResource, module, funcName)
-						o.Pkg.Functions[funcTypeToken] = getterFuncSpec
+						funcName := "get" + resourceName
+						funcTypeToken := o.Pkg.Name + ":" + getModule + ":" + funcName
+						getterFuncSpec, err := o.genGetFunc(currentPath, *pathItem, *respType.Schema, getModule, funcName)
+						if err != nil {
+							o.diagnostics.errorf(CodeUnsupportedSchema, operationPointer(currentPath, http.MethodGet), funcTypeToken, "generating get function: %v", err)
+							return
+						}
+						o.Pkg.Functions[funcTypeToken] = *getterFuncSpec
 						setReadOperationMapping(funcTypeToken)
+						o.functionIdentities[getOperationIdentity(http.MethodGet, currentPath, "")] = funcTypeToken
 					}
-				} else {
-					resourceName := getResourceTitleFromOperationID(pathItem.Get.OperationID, http.MethodGet, o.OperationIDsHaveTypeSpecNamespace)
-					resourceName = getSingularNameForResource(resourceName, o.allowedPluralResources)
-
-					// The resource needs to be read from the cloud provider API,
-					// so we should map this "read" endpoint for this resource.
-					// This is in addition to separately adding the "get" function
-					// too.
-					typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, resourceName)
-					setReadOperationMapping(typeToken)
-
-					funcName := "get" + resourceName
-					funcTypeToken := o.Pkg.Name + ":" + module + ":" + funcName
-					getterFuncSpec := o.genGetFunc(*pathItem, *respType.Schema, module, funcName)
-					o.Pkg.Functions[funcTypeToken] = getterFuncSpec
-					setReadOperationMapping(funcTypeToken)
 				}
-			}
 
-			// Add the API operation as a list* function.
-			if resourceType.Type.Is(openapi3.TypeArray) || strings.Contains(strings.ToLower(pathItem.Get.OperationID), "list") {
-				funcName := "list" + getResourceTitleFromOperationID(pathItem.Get.OperationID, http.MethodGet, o.OperationIDsHaveTypeSpecNamespace)
-				funcTypeToken := o.Pkg.Name + ":" + module + ":" + funcName
-				funcSpec, err := o.genListFunc(*pathItem, *respType.Schema, module, funcName)
-				if err != nil {
-					return nil, o.Doc, errors.Wrap(err, "generating list function")
-				}
+				// Add the API operation as a list* function.
+				if resourceType.Type.Is(openapi3.TypeArray) || strings.Contains(strings.ToLower(pathItem.Get.OperationID), "list") {
+					funcName := "list" + o.getResourceName(getOpExtensions(http.MethodGet), http.MethodGet, pathItem.Get, false)
+					funcTypeToken := o.Pkg.Name + ":" + getModule + ":" + funcName
+					funcSpec, err := o.genListFunc(currentPath, *pathItem, *respType.Schema, getModule, funcName)
+					if err != nil {
+						o.diagnostics.errorf(CodeUnsupportedSchema, operationPointer(currentPath, http.MethodGet), funcTypeToken, "generating list function: %v", err)
+						return
+					}
 
-				o.Pkg.Functions[funcTypeToken] = *funcSpec
-				setReadOperationMapping(funcTypeToken)
-			}
+					o.Pkg.Functions[funcTypeToken] = *funcSpec
+					setReadOperationMapping(funcTypeToken)
+					o.functionIdentities[getOperationIdentity(http.MethodGet, currentPath, "")] = funcTypeToken
+					o.gatherPagination(funcTypeToken, *pathItem, respType.Schema)
+				}
+			}()
 		}
 
 		if pathItem.Patch != nil {
-			if o.exclusionEvaluator.ShouldExclude("PATCH", path) {
-				glog.V(2).Infof("Excluding PATCH %s", path)
-				continue
-			}
+			func() {
+				if o.exclusionEvaluator.ShouldExclude("PATCH", path) {
+					glog.V(2).Infof("Excluding PATCH %s", path)
+					return
+				}
 
-			contract.Assertf(pathItem.Patch.OperationID != "", "operationId is missing for path PATCH %s", currentPath)
+				if pathItem.Patch.OperationID == "" {
+					o.diagnostics.errorf(CodeMissingOperationID, operationPointer(currentPath, http.MethodPatch), "", "operationId is missing for path PATCH %s", currentPath)
+					return
+				}
 
-			glog.V(3).Infof("PATCH: Parent path for %s is %s\n", currentPath, parentPath)
+				patchModule := getOperationModule(http.MethodPatch)
 
-			jsonReq := pathItem.Patch.RequestBody.Value.Content.Get(jsonMimeType)
-			if jsonReq.Schema.Value == nil {
-				contract.Failf("Path %s has no schema definition for Patch method", currentPath)
-			}
+				glog.V(3).Infof("PATCH: Parent path for %s is %s\n", currentPath, parentPath)
 
-			setUpdateOperationMapping := func(tok string) {
-				if existing, ok := o.resourceCRUDMap[tok]; ok {
-					existing.U = &currentPath
-				} else {
-					o.resourceCRUDMap[tok] = &CRUDOperationsMap{
-						U: &currentPath,
+				jsonReq := getRequestMediaType(pathItem.Patch)
+				if jsonReq == nil {
+					o.diagnostics.errorf(CodeMissingRequestSchema, operationPointer(currentPath, http.MethodPatch), "", "path %s has no schema definition for Patch method", currentPath)
+					return
+				}
+
+				setUpdateOperationMapping := func(tok string) {
+					if _, ok := o.resourceCRUDMap[tok]; !ok {
+						o.resourceCRUDMap[tok] = &CRUDOperationsMap{}
 					}
+					o.resourceCRUDMap[tok].U = &currentPath
+					o.resourceCRUDMap[tok].Operations.U = o.newOperationDescriptor(currentPath, http.MethodPatch)
 				}
-			}
 
-			resourceType := jsonReq.Schema.Value
+				resourceType := jsonReq.Schema.Value
 
-			if resourceType.Discriminator != nil || len(resourceType.OneOf) > 0 || len(resourceType.AnyOf) > 0 {
-				schemaNames := codegen.NewStringSet()
-				if resourceType.Discriminator != nil {
-					for _, ref := range resourceType.Discriminator.Mapping {
-						schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
-						schemaNames.Add(schemaName)
+				if resourceType.Discriminator != nil || len(resourceType.OneOf) > 0 || len(resourceType.AnyOf) > 0 {
+					o.warnIgnoredResourceNameExtension(currentPath, http.MethodPatch, getOpExtensions(http.MethodPatch))
+					schemaNames := codegen.NewStringSet()
+					if resourceType.Discriminator != nil {
+						for _, value := range slices.Sorted(maps.Keys(resourceType.Discriminator.Mapping)) {
+							ref := resourceType.Discriminator.Mapping[value]
+							schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
+							schemaNames.Add(schemaName)
+						}
 					}
-				}
 
-				if len(resourceType.OneOf) > 0 {
-					for _, ref := range resourceType.OneOf {
-						schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
-						schemaNames.Add(schemaName)
+					if len(resourceType.OneOf) > 0 {
+						for _, ref := range resourceType.OneOf {
+							schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
+							schemaNames.Add(schemaName)
+						}
 					}
-				}
 
-				if len(resourceType.AnyOf) > 0 {
-					for _, ref := range resourceType.AnyOf {
-						schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
-						schemaNames.Add(schemaName)
+					if len(resourceType.AnyOf) > 0 {
+						for _, ref := range resourceType.AnyOf {
+							schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
+							schemaNames.Add(schemaName)
+						}
 					}
-				}
 
-				for _, n := range schemaNames.SortedValues() {
-					dResource := o.Doc.Components.Schemas[n]
-					resourceName := getResourceTitleFromRequestSchema(n, dResource)
-					typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, resourceName)
+					for _, n := range schemaNames.SortedValues() {
+						dResource := o.Doc.Components.Schemas[n]
+						resourceName := getResourceTitleFromRequestSchema(n, dResource)
+						typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, patchModule, resourceName)
+						setUpdateOperationMapping(typeToken)
+						o.patchRequestSchemas[typeToken] = dResource.Value
+					}
+				} else {
+					resourceName := o.getResourceName(getOpExtensions(http.MethodPatch), http.MethodPatch, pathItem.Patch, true)
+					typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, patchModule, resourceName)
 					setUpdateOperationMapping(typeToken)
+					o.patchRequestSchemas[typeToken] = resourceType
 				}
-			} else {
-				resourceName := getResourceTitleFromOperationID(pathItem.Patch.OperationID, http.MethodPatch, o.OperationIDsHaveTypeSpecNamespace)
-				resourceName = getSingularNameForResource(resourceName, o.allowedPluralResources)
-				typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, resourceName)
-				setUpdateOperationMapping(typeToken)
-			}
+			}()
 		}
 
 		if pathItem.Put != nil {
-			if o.exclusionEvaluator.ShouldExclude("PUT", path) {
-				glog.V(2).Infof("Excluding PUT %s", path)
-				continue
-			}
+			func() {
+				if o.exclusionEvaluator.ShouldExclude("PUT", path) {
+					glog.V(2).Infof("Excluding PUT %s", path)
+					return
+				}
 
-			contract.Assertf(pathItem.Put.OperationID != "", "operationId is missing for path PUT %s", currentPath)
+				if pathItem.Put.OperationID == "" {
+					o.diagnostics.errorf(CodeMissingOperationID, operationPointer(currentPath, http.MethodPut), "", "operationId is missing for path PUT %s", currentPath)
+					return
+				}
 
-			glog.V(3).Infof("PUT: Parent path for %s is %s\n", currentPath, parentPath)
+				putModule := getOperationModule(http.MethodPut)
 
-			jsonReq := pathItem.Put.RequestBody.Value.Content.Get(jsonMimeType)
-			if jsonReq.Schema.Value == nil {
-				contract.Failf("Path %s has no schema definition for Put method", currentPath)
-			}
+				glog.V(3).Infof("PUT: Parent path for %s is %s\n", currentPath, parentPath)
 
-			setPutOperationMapping := func(tok string) {
-				if existing, ok := o.resourceCRUDMap[tok]; ok {
-					existing.P = &currentPath
-				} else {
-					o.resourceCRUDMap[tok] = &CRUDOperationsMap{
-						P: &currentPath,
+				jsonReq := getRequestMediaType(pathItem.Put)
+				if jsonReq == nil {
+					o.diagnostics.errorf(CodeMissingRequestSchema, operationPointer(currentPath, http.MethodPut), "", "path %s has no schema definition for Put method", currentPath)
+					return
+				}
+
+				setPutOperationMapping := func(tok string) {
+					if _, ok := o.resourceCRUDMap[tok]; !ok {
+						o.resourceCRUDMap[tok] = &CRUDOperationsMap{}
 					}
+					o.resourceCRUDMap[tok].P = &currentPath
+					o.resourceCRUDMap[tok].Operations.P = o.newOperationDescriptor(currentPath, http.MethodPut)
 				}
-			}
 
-			resourceType := jsonReq.Schema.Value
+				resourceType := jsonReq.Schema.Value
 
-			if resourceType.Discriminator != nil {
-				for _, ref := range resourceType.Discriminator.Mapping {
-					schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
-					dResource := o.Doc.Components.Schemas[schemaName]
-					resourceName := getResourceTitleFromRequestSchema(schemaName, dResource)
-					typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, resourceName)
+				if resourceType.Discriminator != nil {
+					o.warnIgnoredResourceNameExtension(currentPath, http.MethodPut, getOpExtensions(http.MethodPut))
+					for _, value := range slices.Sorted(maps.Keys(resourceType.Discriminator.Mapping)) {
+						ref := resourceType.Discriminator.Mapping[value]
+						schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
+						dResource := o.Doc.Components.Schemas[schemaName]
+						resourceName := getResourceTitleFromRequestSchema(schemaName, dResource)
+						typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, putModule, resourceName)
+						setPutOperationMapping(typeToken)
+					}
+				} else {
+					resourceName := o.getResourceName(getOpExtensions(http.MethodPut), http.MethodPut, pathItem.Put, false)
+					typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, putModule, resourceName)
 					setPutOperationMapping(typeToken)
 				}
-			} else {
-				resourceName := getResourceTitleFromOperationID(pathItem.Put.OperationID, http.MethodPut, o.OperationIDsHaveTypeSpecNamespace)
-				typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, resourceName)
-				setPutOperationMapping(typeToken)
-			}
+			}()
 		}
 
 		if pathItem.Delete != nil {
-			if o.exclusionEvaluator.ShouldExclude("DELETE", path) {
-				glog.V(2).Infof("Excluding DELETE %s", path)
-				continue
-			}
+			func() {
+				if o.exclusionEvaluator.ShouldExclude("DELETE", path) {
+					glog.V(2).Infof("Excluding DELETE %s", path)
+					return
+				}
 
-			contract.Assertf(pathItem.Delete.OperationID != "", "operationId is missing for path DELETE %s", currentPath)
+				if pathItem.Delete.OperationID == "" {
+					o.diagnostics.errorf(CodeMissingOperationID, operationPointer(currentPath, http.MethodDelete), "", "operationId is missing for path DELETE %s", currentPath)
+					return
+				}
 
-			glog.V(3).Infof("DELETE: Parent path for %s is %s\n", currentPath, parentPath)
+				deleteModule := getOperationModule(http.MethodDelete)
 
-			setDeleteOperationMapping := func(tok string) {
-				if existing, ok := o.resourceCRUDMap[tok]; ok {
-					existing.D = &currentPath
-				} else {
-					o.resourceCRUDMap[tok] = &CRUDOperationsMap{
-						D: &currentPath,
-					}
-				}
-			}
+				glog.V(3).Infof("DELETE: Parent path for %s is %s\n", currentPath, parentPath)
 
-			if pathItem.Delete.RequestBody != nil {
-				jsonReq := pathItem.Delete.RequestBody.Value.Content.Get(jsonMimeType)
-				if jsonReq.Schema.Value == nil {
-					contract.Failf("Path %s has no schema definition for Delete method", currentPath)
+				setDeleteOperationMapping := func(tok string) {
+					if _, ok := o.resourceCRUDMap[tok]; !ok {
+						o.resourceCRUDMap[tok] = &CRUDOperationsMap{}
+					}
+					o.resourceCRUDMap[tok].D = &currentPath
+					o.resourceCRUDMap[tok].Operations.D = o.newOperationDescriptor(currentPath, http.MethodDelete)
 				}
 
-				resourceType := jsonReq.Schema.Value
+				if pathItem.Delete.RequestBody != nil {
+					jsonReq := getRequestMediaType(pathItem.Delete)
+					if jsonReq == nil {
+						o.diagnostics.errorf(CodeMissingRequestSchema, operationPointer(currentPath, http.MethodDelete), "", "path %s has no schema definition for Delete method", currentPath)
+						return
+					}
 
-				if resourceType.Discriminator != nil {
-					for _, ref := range resourceType.Discriminator.Mapping {
-						schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
-						dResource := o.Doc.Components.Schemas[schemaName]
-						resourceName := getResourceTitleFromRequestSchema(schemaName, dResource)
-						typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, resourceName)
+					resourceType := jsonReq.Schema.Value
+
+					if resourceType.Discriminator != nil {
+						o.warnIgnoredResourceNameExtension(currentPath, http.MethodDelete, getOpExtensions(http.MethodDelete))
+						for _, value := range slices.Sorted(maps.Keys(resourceType.Discriminator.Mapping)) {
+							ref := resourceType.Discriminator.Mapping[value]
+							schemaName := strings.TrimPrefix(ref.Ref, componentsSchemaRefPrefix)
+							dResource := o.Doc.Components.Schemas[schemaName]
+							resourceName := getResourceTitleFromRequestSchema(schemaName, dResource)
+							typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, deleteModule, resourceName)
+							setDeleteOperationMapping(typeToken)
+						}
+					} else {
+						resourceName := o.getResourceName(getOpExtensions(http.MethodDelete), http.MethodDelete, pathItem.Delete, true)
+						typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, deleteModule, resourceName)
 						setDeleteOperationMapping(typeToken)
 					}
 				} else {
-					resourceName := getResourceTitleFromOperationID(pathItem.Delete.OperationID, http.MethodDelete, o.OperationIDsHaveTypeSpecNamespace)
-					resourceName = getSingularNameForResource(resourceName, o.allowedPluralResources)
-					typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, resourceName)
+					resourceName := o.getResourceName(getOpExtensions(http.MethodDelete), http.MethodDelete, pathItem.Delete, true)
+					typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, deleteModule, resourceName)
 					setDeleteOperationMapping(typeToken)
 				}
-			} else {
-				resourceName := getResourceTitleFromOperationID(pathItem.Delete.OperationID, http.MethodDelete, o.OperationIDsHaveTypeSpecNamespace)
-				resourceName = getSingularNameForResource(resourceName, o.allowedPluralResources)
-				typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, resourceName)
-				setDeleteOperationMapping(typeToken)
-			}
+			}()
 		}
 
 		if pathItem.Post == nil && pathItem.Put == nil {
@@ -461,9 +672,16 @@ func (o *OpenAPIContext) GatherResourcesFromAPI(csharpNamespaces map[string]stri
 		}
 
 		if pathItem.Post != nil {
-			contract.Assertf(pathItem.Post.OperationID != "", "operationId is missing for path POST %s", currentPath)
+			if pathItem.Post.OperationID == "" {
+				o.diagnostics.errorf(CodeMissingOperationID, operationPointer(currentPath, http.MethodPost), "", "operationId is missing for path POST %s", currentPath)
+				continue
+			}
 		} else if pathItem.Put != nil {
-			contract.Assertf(pathItem.Put.OperationID != "", "operationId is missing for path PUT %s", currentPath)
+			// The missing operationId was already reported
+			// when the PUT operation was converted above.
+			if pathItem.Put.OperationID == "" || o.exclusionEvaluator.ShouldExclude("PUT", path) {
+				continue
+			}
 
 			var parentPathItem *openapi3.PathItem
 			// Because of the way parent path is calculated
@@ -480,16 +698,24 @@ func (o *OpenAPIContext) GatherResourcesFromAPI(csharpNamespaces map[string]stri
 			}
 		}
 
+		createMethod, createOp := http.MethodPost, pathItem.Post
+		if createOp == nil {
+			createMethod, createOp = http.MethodPut, pathItem.Put
+		}
+		createModule := getOperationModule(createMethod)
+
 		var jsonReq *openapi3.MediaType
 		if pathItem.Post != nil && pathItem.Post.RequestBody != nil {
-			jsonReq = pathItem.Post.RequestBody.Value.Content.Get(jsonMimeType)
-			if jsonReq.Schema.Value == nil {
-				return nil, o.Doc, errors.Errorf("path %s has no request body schema for post method", currentPath)
+			jsonReq = getRequestMediaType(pathItem.Post)
+			if jsonReq == nil {
+				o.diagnostics.errorf(CodeMissingRequestSchema, operationPointer(currentPath, http.MethodPost), "", "path %s has no request body schema for post method", currentPath)
+				continue
 			}
 		} else if pathItem.Put != nil && pathItem.Put.RequestBody != nil {
-			jsonReq = pathItem.Put.RequestBody.Value.Content.Get(jsonMimeType)
-			if jsonReq.Schema.Value == nil {
-				return nil, o.Doc, errors.Errorf("path %s has no request body schema for put method", currentPath)
+			jsonReq = getRequestMediaType(pathItem.Put)
+			if jsonReq == nil {
+				o.diagnostics.errorf(CodeMissingRequestSchema, operationPointer(currentPath, http.MethodPut), "", "path %s has no request body schema for put method", currentPath)
+				continue
 			}
 		} else {
 			jsonReq = openapi3.NewMediaType().WithSchema(openapi3.NewSchema())
@@ -498,7 +724,8 @@ func (o *OpenAPIContext) GatherResourcesFromAPI(csharpNamespaces map[string]stri
 		// 201 and 202 status codes contain provisional response bodies.
 		// For example, DigitalOcean responds with 202 for a request
 		// to provision Floating IPs that may not be fully
-		// provisioned yet.
+		// provisioned yet. See gatherLongRunningOperations for how
+		// the provider is told to wait for them.
 		responseCodes := []int{200, 201, 202}
 		var statusCodeOkResp *openapi3.ResponseRef
 		for _, code := range responseCodes {
@@ -524,7 +751,8 @@ func (o *OpenAPIContext) GatherResourcesFromAPI(csharpNamespaces map[string]stri
 				if jsonResp.Schema.Ref != "" && jsonResp.Schema.Value == nil {
 					v, err := o.Doc.Components.Schemas.JSONLookup(strings.TrimPrefix(jsonResp.Schema.Ref, componentsSchemaRefPrefix))
 					if err != nil {
-						return nil, o.Doc, err
+						o.diagnostics.errorf(CodeMissingSchema, operationPointer(currentPath, createMethod), "", "looking up response schema %s: %v", jsonResp.Schema.Ref, err)
+						continue
 					}
 					resourceResponseType = v.(*openapi3.Schema)
 				} else {
@@ -533,77 +761,234 @@ func (o *OpenAPIContext) GatherResourcesFromAPI(csharpNamespaces map[string]stri
 			}
 		}
 
-		var resourceName string
-		var parameters openapi3.Parameters
-		if pathItem.Post != nil {
-			resourceName = getResourceTitleFromOperationID(pathItem.Post.OperationID, http.MethodPost, o.OperationIDsHaveTypeSpecNamespace)
-			resourceName = getSingularNameForResource(resourceName, o.allowedPluralResources)
-			parameters = append(pathItem.Parameters, pathItem.Post.Parameters...)
-		} else if pathItem.Put != nil {
-			resourceName = getResourceTitleFromOperationID(pathItem.Put.OperationID, http.MethodPut, o.OperationIDsHaveTypeSpecNamespace)
-			resourceName = getSingularNameForResource(resourceName, o.allowedPluralResources)
-			parameters = append(pathItem.Parameters, pathItem.Put.Parameters...)
-		}
+		resourceName := o.getResourceName(getOpExtensions(createMethod), createMethod, createOp, true)
+		parameters := append(pathItem.Parameters, createOp.Parameters...)
 
 		resourceRequestType := jsonReq.Schema.Value
-		if err := o.gatherResource(currentPath, resourceName, *resourceRequestType, resourceResponseType, parameters, module); err != nil {
-			return nil, o.Doc, errors.Wrapf(err, "generating resource for api path %s", currentPath)
+		if resourceRequestType.Discriminator != nil {
+			o.warnIgnoredResourceNameExtension(currentPath, createMethod, getOpExtensions(createMethod))
+		}
+		if err := o.gatherResource(currentPath, createMethod, resourceName, *resourceRequestType, resourceResponseType, parameters, createModule); err != nil {
+			o.diagnostics.errorf(CodeUnsupportedSchema, operationPointer(currentPath, createMethod), fmt.Sprintf("%s:%s:%s", o.Pkg.Name, createModule, resourceName), "generating resource for api path %s: %v", currentPath, err)
 		}
 	}
 
+	o.applyNamingLock(csharpNamespaces)
+	o.gatherResourceHeaderParams()
+	o.gatherReplaceOnChanges()
+	o.gatherLongRunningOperations()
+	o.gatherBinaryProperties()
+	o.gatherResourceAliases()
+	o.deduplicateTypes(csharpNamespaces)
+
+	if o.diagnostics.diags.HasErrors() {
+		return nil, o.Doc, o.diagnostics.diags
+	}
+
+	return o.newProviderMetadata(), o.Doc, nil
+}
+
+// resetState initializes the maps and the diagnostics collected
+// during a conversion.
+func (o *OpenAPIContext) resetState() {
+	o.resourceCRUDMap = make(map[string]*CRUDOperationsMap)
+	o.autoNameMap = make(map[string]string)
+	o.autoNameConstraintsMap = make(map[string]*AutoNameConstraints)
+	o.visitedTypes = codegen.NewStringSet()
+	o.renamedTypes = make(map[string]string)
+	o.sdkToAPINameMap = make(map[string]string)
+	o.apiToSDKNameMap = make(map[string]string)
+	o.pathParamNameMap = make(map[string]string)
+	o.patchRequestSchemas = make(map[string]*openapi3.Schema)
+	o.replaceOnChangesMap = make(map[string][]string)
+	o.patchablePropertiesMap = make(map[string][]string)
+	o.longRunningOperationsMap = make(map[string]*LongRunningOperations)
+	o.queryParamsMap = make(map[string]map[string]string)
+	o.headerParamsMap = make(map[string]map[string]string)
+	o.providerHeaderParamsMap = make(map[string]string)
+	o.providerHeaderParams = codegen.NewStringSet()
+	o.paginationMap = make(map[string]*Pagination)
+	o.writeOnlyPropertiesMap = make(map[string][]string)
+	o.binaryPropertiesMap = make(map[string]map[string]*BinaryEncoding)
+	o.validationConstraintsMap = make(map[string]map[string]*ValidationConstraints)
+	o.resourceIdentities = make(map[string]string)
+	o.functionIdentities = make(map[string]string)
+	o.diagnostics = &diagnosticsCollector{strict: o.Strict}
+}
+
+// newProviderMetadata returns the provider metadata collected
+// during the conversion.
+func (o *OpenAPIContext) newProviderMetadata() *ProviderMetadata {
 	return &ProviderMetadata{
-		ResourceCRUDMap:  o.resourceCRUDMap,
-		AutoNameMap:      o.autoNameMap,
-		SDKToAPINameMap:  o.sdkToAPINameMap,
-		APIToSDKNameMap:  o.apiToSDKNameMap,
-		PathParamNameMap: o.pathParamNameMap,
-	}, o.Doc, nil
+		ResourceCRUDMap:          o.resourceCRUDMap,
+		AutoNameMap:              o.autoNameMap,
+		AutoNameConstraintsMap:   o.autoNameConstraintsMap,
+		SDKToAPINameMap:          o.sdkToAPINameMap,
+		APIToSDKNameMap:          o.apiToSDKNameMap,
+		PathParamNameMap:         o.pathParamNameMap,
+		ReplaceOnChangesMap:      o.replaceOnChangesMap,
+		PatchablePropertiesMap:   o.patchablePropertiesMap,
+		LongRunningOperationsMap: o.longRunningOperationsMap,
+		QueryParamsMap:           o.queryParamsMap,
+		HeaderParamsMap:          o.headerParamsMap,
+		ProviderHeaderParamsMap:  o.providerHeaderParamsMap,
+		PaginationMap:            o.paginationMap,
+		WriteOnlyPropertiesMap:   o.writeOnlyPropertiesMap,
+		BinaryPropertiesMap:      o.binaryPropertiesMap,
+		ValidationConstraintsMap: o.validationConstraintsMap,
+	}
 }
 
-// genListFunc returns a function spec for a GET API endpoint that returns a list of objects.
-// The item type can have a discriminator in the schema. This method will return a type
-// that will refer to an output type that uses the discriminator properties to correctly
-// type the output result.
-func (o *OpenAPIContext) genListFunc(pathItem openapi3.PathItem, returnTypeSchema openapi3.SchemaRef, module, funcName string) (*pschema.FunctionSpec, error) {
-	parentName := ToPascalCase(funcName)
-	if funcName == "listReservedIPs" {
-		glog.Info("HELLO!")
+// Diagnostics returns the problems found during the last call to
+// GatherResourcesFromAPI.
+func (o *OpenAPIContext) Diagnostics() Diagnostics {
+	if o.diagnostics == nil {
+		return nil
 	}
-	funcPkgCtx := &resourceContext{
+	return o.diagnostics.diags
+}
+
+// prefixModule returns the module with the modulePrefix, if any.
+func (o *OpenAPIContext) prefixModule(module string) string {
+	if o.modulePrefix == "" {
+		return module
+	}
+	return o.modulePrefix + "/" + module
+}
+
+// getResourceName returns the resource name pinned on the operation
+// using the ExtResourceName extension, if any. Otherwise, the name is
+// derived from the operationId and, if singular is true, converted to
+// its singular form.
+func (o *OpenAPIContext) getResourceName(ext *operationExtensions, method string, op *openapi3.Operation, singular bool) string {
+	if ext.resourceName != "" {
+		return ext.resourceName
+	}
+
+	resourceName := getResourceTitleFromOperationID(op.OperationID, method, o.OperationIDsHaveTypeSpecNamespace)
+	if singular {
+		resourceName = getSingularNameForResource(resourceName, o.allowedPluralResources)
+	}
+
+	return resourceName
+}
+
+// warnIgnoredResourceNameExtension reports a warning if the ExtResourceName
+// extension is set on an operation whose schema maps to multiple resources
+// through a discriminator, since the resource names then come from the
+// discriminated schemas. The warning is reported once per operation.
+func (o *OpenAPIContext) warnIgnoredResourceNameExtension(apiPath, method string, ext *operationExtensions) {
+	if !ext.hasResourceName || ext.ignoredResourceNameReported {
+		return
+	}
+
+	ext.ignoredResourceNameReported = true
+	o.diagnostics.warnf(CodeInvalidExtension, operationPointer(apiPath, method), "", "%s is ignored because the operation maps to multiple resources using a discriminator", ExtResourceName)
+}
+
+// operationExtensions are the resolved values of the extensions
+// that pin the resource name and module of an operation.
+type operationExtensions struct {
+	resourceName string
+	module       string
+	// hasResourceName is true if the ExtResourceName extension
+	// is set, even if its value is invalid.
+	hasResourceName bool
+	// ignoredResourceNameReported is true once the warning about
+	// an ignored ExtResourceName extension has been reported.
+	ignoredResourceNameReported bool
+}
+
+// getOperationExtensions returns the resolved ExtResourceName and
+// ExtModule extensions of an operation.
+func (o *OpenAPIContext) getOperationExtensions(apiPath, method string, op *openapi3.Operation) *operationExtensions {
+	ext := &operationExtensions{}
+	if op == nil {
+		return ext
+	}
+
+	_, ext.hasResourceName = op.Extensions[ExtResourceName]
+	ext.resourceName, _ = o.getStringExtension(apiPath, method, op, ExtResourceName)
+	ext.module, _ = o.getStringExtension(apiPath, method, op, ExtModule)
+
+	return ext
+}
+
+// getStringExtension returns the value of a string extension on an operation.
+func (o *OpenAPIContext) getStringExtension(apiPath, method string, op *openapi3.Operation, name string) (string, bool) {
+	v, ok := op.Extensions[name]
+	if !ok {
+		return "", false
+	}
+
+	s, ok := v.(string)
+	if !ok || s == "" {
+		o.diagnostics.warnf(CodeInvalidExtension, operationPointer(apiPath, method), "", "%s must be a non-empty string but got %v", name, v)
+		return "", false
+	}
+
+	return s, true
+}
+
+// newResourceContext returns a resourceContext for converting the
+// schemas of the operation identified by pointer.
+func (o *OpenAPIContext) newResourceContext(module, resourceName, pointer string) *resourceContext {
+	return &resourceContext{
 		mod:               module,
 		pkg:               o.Pkg,
+		resourceName:      resourceName,
 		openapiComponents: *o.Doc.Components,
 		visitedTypes:      o.visitedTypes,
 		sdkToAPINameMap:   o.sdkToAPINameMap,
 		apiToSDKNameMap:   o.apiToSDKNameMap,
 		pathParamMap:      o.pathParamNameMap,
+		diagnostics:       o.diagnostics,
+		pointer:           pointer,
+
+		typeCollisionStrategy: o.TypeCollisionStrategy,
+		renamedTypes:          o.renamedTypes,
 	}
+}
 
-	requiredInputs := codegen.NewStringSet()
-	inputProps := make(map[string]pschema.PropertySpec)
+// getRequestMediaType returns the media type of an operation's request
+// body or nil if it doesn't have a JSON or multipart/form-data one with
+// a schema. A request body that is just a file, such as
+// `application/octet-stream`, is returned as an object with a single
+// rawBodyPropertyName property.
+func getRequestMediaType(op *openapi3.Operation) *openapi3.MediaType {
+	if op.RequestBody == nil || op.RequestBody.Value == nil {
+		return nil
+	}
 
-	parameters := pathItem.Parameters
-	parameters = append(parameters, pathItem.Get.Parameters...)
-	for _, param := range parameters {
-		if param.Value.In != parameterLocationPath {
-			continue
-		}
+	contentType := getPreferredContentType(op.RequestBody.Value.Content)
+	req := op.RequestBody.Value.Content.Get(contentType)
+	if req == nil || req.Schema == nil || req.Schema.Value == nil {
+		return nil
+	}
 
-		paramName := param.Value.Name
-		sdkName := ToSdkName(paramName)
+	switch {
+	case contentType == jsonMimeType, contentType == multipartFormDataMimeType:
+		return req
+	case isBinarySchema(req.Schema.Value):
+		return newRawBodyMediaType(req.Schema)
+	}
 
-		if sdkName != paramName {
-			addNameOverride(sdkName, paramName, o.sdkToAPINameMap)
-			addNameOverride(paramName, sdkName, o.apiToSDKNameMap)
-			addNameOverride(paramName, sdkName, o.pathParamNameMap)
-		}
+	return nil
+}
 
-		inputProps[sdkName] = pschema.PropertySpec{
-			Description: param.Value.Description,
-			TypeSpec:    pschema.TypeSpec{Type: typeString},
-		}
-		requiredInputs.Add(sdkName)
-	}
+// genListFunc returns a function spec for a GET API endpoint that returns a list of objects.
+// The item type can have a discriminator in the schema. This method will return a type
+// that will refer to an output type that uses the discriminator properties to correctly
+// type the output result.
+func (o *OpenAPIContext) genListFunc(apiPath string, pathItem openapi3.PathItem, returnTypeSchema openapi3.SchemaRef, module, funcName string) (*pschema.FunctionSpec, error) {
+	parentName := ToPascalCase(funcName)
+	funcPkgCtx := o.newResourceContext(module, "", operationPointer(apiPath, http.MethodGet))
+
+	parameters := pathItem.Parameters
+	parameters = append(parameters, pathItem.Get.Parameters...)
+	inputProps, requiredInputs := o.genFunctionInputs(funcPkgCtx, o.Pkg.Name+":"+module+":"+funcName, parameters)
+	description := getOperationDescription(&pathItem, pathItem.Get)
+	deprecationMessage := getDeprecationMessage("function", pathItem.Get.Deprecated, pathItem.Get.Description, pathItem.Get.Extensions)
 
 	outputPropType, _, err := funcPkgCtx.propertyTypeSpec(parentName, returnTypeSchema)
 	if err != nil {
@@ -649,7 +1034,8 @@ func (o *OpenAPIContext) genListFunc(pathItem openapi3.PathItem, returnTypeSchem
 	}
 
 	return &pschema.FunctionSpec{
-		Description: pathItem.Description,
+		Description:        description,
+		DeprecationMessage: deprecationMessage,
 		Inputs: &pschema.ObjectTypeSpec{
 			Properties: inputProps,
 			Required:   requiredInputs.SortedValues(),
@@ -662,62 +1048,35 @@ func (o *OpenAPIContext) genListFunc(pathItem openapi3.PathItem, returnTypeSchem
 // The single object can have a discriminator in the schema. This method will return a type
 // that will refer to an output type that uses the discriminator properties to correctly
 // type the output result.
-func (o *OpenAPIContext) genGetFunc(pathItem openapi3.PathItem, returnTypeSchema openapi3.SchemaRef, module, funcName string) pschema.FunctionSpec {
+func (o *OpenAPIContext) genGetFunc(apiPath string, pathItem openapi3.PathItem, returnTypeSchema openapi3.SchemaRef, module, funcName string) (*pschema.FunctionSpec, error) {
 	parentName := ToPascalCase(funcName)
-	funcPkgCtx := &resourceContext{
-		mod:               module,
-		pkg:               o.Pkg,
-		openapiComponents: *o.Doc.Components,
-		visitedTypes:      o.visitedTypes,
-		sdkToAPINameMap:   o.sdkToAPINameMap,
-		apiToSDKNameMap:   o.apiToSDKNameMap,
-		pathParamMap:      o.pathParamNameMap,
-	}
-
-	requiredInputs := codegen.NewStringSet()
-	inputProps := make(map[string]pschema.PropertySpec)
+	funcPkgCtx := o.newResourceContext(module, "", operationPointer(apiPath, http.MethodGet))
 
 	parameters := pathItem.Parameters
 	parameters = append(parameters, pathItem.Get.Parameters...)
-
-	for _, param := range parameters {
-		if param.Value.In != parameterLocationPath {
-			continue
-		}
-
-		paramName := param.Value.Name
-		sdkName := ToSdkName(paramName)
-
-		if sdkName != paramName {
-			addNameOverride(sdkName, paramName, o.sdkToAPINameMap)
-			addNameOverride(paramName, sdkName, o.apiToSDKNameMap)
-			addNameOverride(paramName, sdkName, o.pathParamNameMap)
-		}
-
-		inputProps[sdkName] = pschema.PropertySpec{
-			Description: param.Value.Description,
-			TypeSpec:    pschema.TypeSpec{Type: typeString},
-		}
-		requiredInputs.Add(sdkName)
-	}
+	inputProps, requiredInputs := o.genFunctionInputs(funcPkgCtx, o.Pkg.Name+":"+module+":"+funcName, parameters)
+	description := getOperationDescription(&pathItem, pathItem.Get)
+	deprecationMessage := getDeprecationMessage("function", pathItem.Get.Deprecated, pathItem.Get.Description, pathItem.Get.Extensions)
 
 	if returnTypeSchema.Value == nil || returnTypeSchema.Value == defaultEmptySchemaDoNotMutate {
-		return pschema.FunctionSpec{
-			Description: pathItem.Description,
+		return &pschema.FunctionSpec{
+			Description:        description,
+			DeprecationMessage: deprecationMessage,
 			Inputs: &pschema.ObjectTypeSpec{
 				Properties: inputProps,
 				Required:   requiredInputs.SortedValues(),
 			},
-		}
+		}, nil
 	}
 
 	outputPropType, _, err := funcPkgCtx.propertyTypeSpec(parentName, returnTypeSchema)
 	if err != nil {
-		panic(err)
+		return nil, errors.Wrap(err, "generating property type spec for response schema")
 	}
 
-	return pschema.FunctionSpec{
-		Description: pathItem.Description,
+	return &pschema.FunctionSpec{
+		Description:        description,
+		DeprecationMessage: deprecationMessage,
 		Inputs: &pschema.ObjectTypeSpec{
 			Properties: inputProps,
 			Required:   requiredInputs.SortedValues(),
@@ -725,19 +1084,22 @@ func (o *OpenAPIContext) genGetFunc(pathItem openapi3.PathItem, returnTypeSchema
 		ReturnType: &pschema.ReturnTypeSpec{
 			TypeSpec: outputPropType,
 		},
-	}
+	}, nil
 }
 
 // gatherResource generates a resource spec from a POST API endpoint schema and
 // adds it to the Pulumi schema spec.
 func (o *OpenAPIContext) gatherResource(
 	apiPath string,
+	method string,
 	resourceName string,
 	resourceRequestType openapi3.Schema,
 	resourceResponseType *openapi3.Schema,
 	pathParams openapi3.Parameters,
 	module string) error {
 
+	pkgCtx := o.newResourceContext(module, resourceName, operationPointer(apiPath, method))
+
 	addRequiredPathParams := func(typeToken string) {
 		resourceSpec := o.Pkg.Resources[typeToken]
 
@@ -752,14 +1114,18 @@ func (o *OpenAPIContext) gatherResource(
 			sdkName := ToSdkName(paramName)
 
 			if sdkName != paramName {
-				addNameOverride(sdkName, paramName, o.sdkToAPINameMap)
-				addNameOverride(paramName, sdkName, o.apiToSDKNameMap)
-				addNameOverride(paramName, sdkName, o.pathParamNameMap)
+				pkgCtx.addNameOverride(sdkName, paramName, o.sdkToAPINameMap)
+				pkgCtx.addNameOverride(paramName, sdkName, o.apiToSDKNameMap)
+				pkgCtx.addNameOverride(paramName, sdkName, o.pathParamNameMap)
 			}
 
 			resourceSpec.InputProperties[sdkName] = pschema.PropertySpec{
-				Description: param.Value.Description,
-				TypeSpec:    pschema.TypeSpec{Type: typeString},
+				Description:        param.Value.Description,
+				DeprecationMessage: getDeprecationMessage("property", param.Value.Deprecated, param.Value.Description, param.Value.Extensions),
+				TypeSpec:           pschema.TypeSpec{Type: typeString},
+			}
+			if param.Value.Schema != nil {
+				o.addValidationConstraints(typeToken, sdkName, param.Value.Schema.Value, map[*openapi3.Schema]bool{})
 			}
 		}
 
@@ -767,7 +1133,8 @@ func (o *OpenAPIContext) gatherResource(
 	}
 
 	if resourceRequestType.Discriminator != nil {
-		for discriminatedValue, mappingRef := range resourceRequestType.Discriminator.Mapping {
+		for _, discriminatedValue := range slices.Sorted(maps.Keys(resourceRequestType.Discriminator.Mapping)) {
+			mappingRef := resourceRequestType.Discriminator.Mapping[discriminatedValue]
 			schemaName := strings.TrimPrefix(mappingRef.Ref, componentsSchemaRefPrefix)
 			typeSchema, ok := o.Doc.Components.Schemas[schemaName]
 			if !ok {
@@ -786,9 +1153,9 @@ func (o *OpenAPIContext) gatherResource(
 				if !ok {
 					return errors.Errorf("response schema type %s not found", responseSchemaName)
 				}
-				resourceTypeToken, err = o.gatherResourceProperties(discriminatedResourceName, *typeSchema.Value, responseTypeSchema.Value, apiPath, module)
+				resourceTypeToken, err = o.gatherResourceProperties(discriminatedResourceName, *typeSchema.Value, responseTypeSchema.Value, apiPath, method, module)
 			} else {
-				resourceTypeToken, err = o.gatherResourceProperties(discriminatedResourceName, *typeSchema.Value, resourceResponseType, apiPath, module)
+				resourceTypeToken, err = o.gatherResourceProperties(discriminatedResourceName, *typeSchema.Value, resourceResponseType, apiPath, method, module)
 			}
 
 			if err != nil {
@@ -796,6 +1163,7 @@ func (o *OpenAPIContext) gatherResource(
 			}
 
 			addRequiredPathParams(*resourceTypeToken)
+			o.resourceIdentities[getOperationIdentity(method, apiPath, mappingRef.Ref)] = *resourceTypeToken
 		}
 
 		return nil
@@ -810,30 +1178,25 @@ func (o *OpenAPIContext) gatherResource(
 		resourceRequestType.AllOf = schemaRefs
 	}
 
-	resourceTypeToken, err := o.gatherResourceProperties(resourceName, resourceRequestType, resourceResponseType, apiPath, module)
+	resourceTypeToken, err := o.gatherResourceProperties(resourceName, resourceRequestType, resourceResponseType, apiPath, method, module)
 
 	if err != nil {
 		return errors.Wrapf(err, "gathering resource from api path %s", apiPath)
 	}
 
 	addRequiredPathParams(*resourceTypeToken)
+	o.resourceIdentities[getOperationIdentity(method, apiPath, "")] = *resourceTypeToken
 
 	return nil
 }
 
 // gatherResourceProperties generates a resource spec's input and output properties
 // based on its API schema. Returns the Pulumi type token for the newly-added resource.
-func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBodySchema openapi3.Schema, responseBodySchema *openapi3.Schema, apiPath, module string) (*string, error) {
-	pkgCtx := &resourceContext{
-		mod:               module,
-		pkg:               o.Pkg,
-		resourceName:      resourceName,
-		openapiComponents: *o.Doc.Components,
-		visitedTypes:      o.visitedTypes,
-		sdkToAPINameMap:   o.sdkToAPINameMap,
-		apiToSDKNameMap:   o.apiToSDKNameMap,
-		pathParamMap:      o.pathParamNameMap,
-	}
+func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBodySchema openapi3.Schema, responseBodySchema *openapi3.Schema, apiPath, method, module string) (*string, error) {
+	pkgCtx := o.newResourceContext(module, resourceName, operationPointer(apiPath, method))
+	pkgCtx.binaryAsAssets = true
+	// The files returned in the response body are just strings.
+	respCtx := o.newResourceContext(module, resourceName, operationPointer(apiPath, method))
 
 	inputProperties := make(map[string]pschema.PropertySpec)
 	properties := make(map[string]pschema.PropertySpec)
@@ -841,8 +1204,10 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 	requiredOutputs := codegen.NewStringSet()
 	typeToken := fmt.Sprintf("%s:%s:%s", o.Pkg.Name, module, resourceName)
 
-	for propName, prop := range requestBodySchema.Properties {
+	for _, propName := range slices.Sorted(maps.Keys(requestBodySchema.Properties)) {
+		prop := requestBodySchema.Properties[propName]
 		var propSpec pschema.PropertySpec
+		var err error
 
 		if prop.Value.AdditionalProperties.Has != nil {
 			allowed := *prop.Value.AdditionalProperties.Has
@@ -865,16 +1230,21 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 					}
 				}
 			} else {
-				propSpec = pkgCtx.genPropertySpec(ToPascalCase(propName), *prop)
+				propSpec, err = pkgCtx.genPropertySpec(ToPascalCase(propName), *prop)
 			}
 		} else {
-			propSpec = pkgCtx.genPropertySpec(ToPascalCase(propName), *prop)
+			propSpec, err = pkgCtx.genPropertySpec(ToPascalCase(propName), *prop)
+		}
+
+		if err != nil {
+			o.diagnostics.errorf(CodeUnsupportedSchema, pkgCtx.pointer, typeToken, "skipping input property %s: %v", propName, err)
+			continue
 		}
 
 		sdkName := ToSdkName(propName)
 		if sdkName != propName {
-			addNameOverride(sdkName, propName, o.sdkToAPINameMap)
-			addNameOverride(propName, sdkName, o.apiToSDKNameMap)
+			pkgCtx.addNameOverride(sdkName, propName, o.sdkToAPINameMap)
+			pkgCtx.addNameOverride(propName, sdkName, o.apiToSDKNameMap)
 		}
 
 		// Skip read-only properties and `id` properties as direct inputs for resources.
@@ -894,7 +1264,7 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 
 	if responseBodySchema != nil {
 		if len(responseBodySchema.AllOf) > 0 {
-			allOfProps, _, err := pkgCtx.genPropertiesFromAllOf(resourceName, responseBodySchema.AllOf)
+			allOfProps, _, err := respCtx.genPropertiesFromAllOf(resourceName, responseBodySchema.AllOf)
 			if err != nil {
 				return nil, errors.Wrapf(err, "generating properties from response type allOf definition (resource %s, path: %s)", resourceName, apiPath)
 			}
@@ -907,8 +1277,10 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 			}
 		}
 
-		for propName, prop := range responseBodySchema.Properties {
+		for _, propName := range slices.Sorted(maps.Keys(responseBodySchema.Properties)) {
+			prop := responseBodySchema.Properties[propName]
 			var propSpec pschema.PropertySpec
+			var err error
 
 			if prop.Value.AdditionalProperties.Has != nil {
 				allowed := *prop.Value.AdditionalProperties.Has
@@ -918,7 +1290,7 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 					// properties schema or have a type ref. Either way,
 					// the `propertyTypeSpec` method will take care of it.
 					for _, v := range prop.Value.Properties {
-						typeSpec, _, err := pkgCtx.propertyTypeSpec(propName, *v)
+						typeSpec, _, err := respCtx.propertyTypeSpec(propName, *v)
 						if err != nil {
 							return nil, errors.Wrapf(err, "generating additional properties type spec for %s (path: %s)", propName, apiPath)
 						}
@@ -931,16 +1303,21 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 						}
 					}
 				} else {
-					propSpec = pkgCtx.genPropertySpec(ToPascalCase(propName), *prop)
+					propSpec, err = respCtx.genPropertySpec(ToPascalCase(propName), *prop)
 				}
 			} else {
-				propSpec = pkgCtx.genPropertySpec(ToPascalCase(propName), *prop)
+				propSpec, err = respCtx.genPropertySpec(ToPascalCase(propName), *prop)
+			}
+
+			if err != nil {
+				o.diagnostics.errorf(CodeUnsupportedSchema, respCtx.pointer, typeToken, "skipping output property %s: %v", propName, err)
+				continue
 			}
 
 			sdkName := ToSdkName(propName)
 			if sdkName != propName {
-				addNameOverride(sdkName, propName, o.sdkToAPINameMap)
-				addNameOverride(propName, sdkName, o.apiToSDKNameMap)
+				respCtx.addNameOverride(sdkName, propName, o.sdkToAPINameMap)
+				respCtx.addNameOverride(propName, sdkName, o.apiToSDKNameMap)
 			}
 
 			// If the cloud API nests the response inside a property
@@ -979,6 +1356,20 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 		}
 	}
 
+	// The auto-named property is not strictly required as Pulumi
+	// can auto-name it based on the Pulumi resource name.
+	autoNameProp := o.getAutoNameProperty(pkgCtx.pointer, typeToken, requestBodySchema)
+	if autoNameProp != "" {
+		sdkName := ToSdkName(autoNameProp)
+		if existing, ok := o.autoNameMap[typeToken]; ok && existing != sdkName {
+			return nil, errors.Errorf("auto-name prop already exists for resource %s (existing: %s, new: %s)", typeToken, existing, sdkName)
+		}
+		o.autoNameMap[typeToken] = sdkName
+		if c := getAutoNameConstraints(requestBodySchema.Properties[autoNameProp].Value); c != nil {
+			o.autoNameConstraintsMap[typeToken] = c
+		}
+	}
+
 	// Create a set of required inputs for this resource.
 	// Filter out required props that are marked as read-only.
 	for _, requiredProp := range requestBodySchema.Required {
@@ -992,29 +1383,18 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 		// (or should) have this property already,
 		// so ignore it.
 		if propSchema == nil {
-			glog.Warningf("Schema not found for required property: %s (type: %s)", requiredProp, resourceName)
+			o.diagnostics.warnf(CodeMissingSchema, pkgCtx.pointer, typeToken, "schema not found for required property %s", requiredProp)
 			continue
 		}
 
-		// `name` property is not strictly required as Pulumi can auto-name it
-		// based on the Pulumi resource name.
-		if propSchema.Value.ReadOnly {
-			continue
-		}
-
-		if requiredProp == propertyName {
-			if autoNameProp, ok := o.autoNameMap[typeToken]; ok && autoNameProp != requiredProp {
-				return nil, errors.Errorf("auto-name prop already exists for resource %s (existing: %s, new: %s)", typeToken, autoNameProp, requiredProp)
-			}
-			o.autoNameMap[typeToken] = propertyName
-
+		if propSchema.Value.ReadOnly || isNullUnion(propSchema.Value) || requiredProp == autoNameProp {
 			continue
 		}
 
 		sdkName := ToSdkName(requiredProp)
 		if sdkName != requiredProp {
-			addNameOverride(sdkName, requiredProp, o.sdkToAPINameMap)
-			addNameOverride(requiredProp, sdkName, o.apiToSDKNameMap)
+			pkgCtx.addNameOverride(sdkName, requiredProp, o.sdkToAPINameMap)
+			pkgCtx.addNameOverride(requiredProp, sdkName, o.apiToSDKNameMap)
 		}
 
 		requiredInputs.Add(sdkName)
@@ -1027,10 +1407,14 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 	// read-only in which case, they wouldn't have been
 	// added to the `requiredInputs` set.
 	for _, requiredProp := range requestBodySchema.Required {
+		if p, ok := requestBodySchema.Properties[requiredProp]; ok && isNullUnion(p.Value) {
+			continue
+		}
+
 		sdkName := ToSdkName(requiredProp)
 		if sdkName != requiredProp {
-			addNameOverride(sdkName, requiredProp, o.sdkToAPINameMap)
-			addNameOverride(requiredProp, sdkName, o.apiToSDKNameMap)
+			pkgCtx.addNameOverride(sdkName, requiredProp, o.sdkToAPINameMap)
+			pkgCtx.addNameOverride(requiredProp, sdkName, o.apiToSDKNameMap)
 		}
 		requiredOutputs.Add(sdkName)
 	}
@@ -1041,10 +1425,13 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 			if requiredProp == "id" {
 				continue
 			}
+			if p, ok := responseBodySchema.Properties[requiredProp]; ok && isNullUnion(p.Value) {
+				continue
+			}
 			sdkName := ToSdkName(requiredProp)
 			if sdkName != requiredProp {
-				addNameOverride(sdkName, requiredProp, o.sdkToAPINameMap)
-				addNameOverride(requiredProp, sdkName, o.apiToSDKNameMap)
+				pkgCtx.addNameOverride(sdkName, requiredProp, o.sdkToAPINameMap)
+				pkgCtx.addNameOverride(requiredProp, sdkName, o.apiToSDKNameMap)
 			}
 			requiredOutputs.Add(sdkName)
 		}
@@ -1104,23 +1491,34 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 		}
 	}
 
-	if existing, ok := o.resourceCRUDMap[typeToken]; ok {
-		existing.C = &apiPath
-	} else {
-		o.resourceCRUDMap[typeToken] = &CRUDOperationsMap{
-			C: &apiPath,
-		}
+	o.gatherWriteOnlyProperties(typeToken, &requestBodySchema, responseBodySchema, inputProperties, properties, requiredOutputs)
+	o.gatherValidationConstraints(typeToken, &requestBodySchema, inputProperties)
+
+	if _, ok := o.resourceCRUDMap[typeToken]; !ok {
+		o.resourceCRUDMap[typeToken] = &CRUDOperationsMap{}
+	}
+	o.resourceCRUDMap[typeToken].C = &apiPath
+	o.resourceCRUDMap[typeToken].Operations.C = o.newOperationDescriptor(apiPath, method)
+
+	// The resource is deprecated if its create
+	// operation or its schema is deprecated.
+	createPathItem := o.Doc.Paths.Find(apiPath)
+	createOp := createPathItem.GetOperation(method)
+	deprecationMessage := getDeprecationMessage("resource", createOp.Deprecated, createOp.Description, createOp.Extensions)
+	if deprecationMessage == "" {
+		deprecationMessage = getDeprecationMessage("resource", requestBodySchema.Deprecated, requestBodySchema.Description, requestBodySchema.Extensions)
 	}
 
 	o.Pkg.Resources[typeToken] = pschema.ResourceSpec{
 		ObjectTypeSpec: pschema.ObjectTypeSpec{
-			Description: requestBodySchema.Description,
+			Description: joinDescriptions(getSchemaDescription(&requestBodySchema), getOperationDescription(createPathItem, createOp)),
 			Type:        typeObject,
 			Properties:  properties,
 			Required:    requiredOutputs.SortedValues(),
 		},
-		InputProperties: inputProperties,
-		RequiredInputs:  requiredInputs.SortedValues(),
+		InputProperties:    inputProperties,
+		RequiredInputs:     requiredInputs.SortedValues(),
+		DeprecationMessage: deprecationMessage,
 	}
 
 	return &typeToken, nil
@@ -1132,19 +1530,18 @@ func (o *OpenAPIContext) gatherResourceProperties(resourceName string, requestBo
 // within the schema. In the case of ref's to other types, those
 // other types are automatically added to the Pulumi schema spec's
 // `Types` property.
-func (ctx *resourceContext) genPropertySpec(propName string, p openapi3.SchemaRef) pschema.PropertySpec {
+func (ctx *resourceContext) genPropertySpec(propName string, p openapi3.SchemaRef) (pschema.PropertySpec, error) {
+	p = normalizeSchemaRef(p)
 	propertySpec := pschema.PropertySpec{
-		Description: p.Value.Description,
+		Description:        getSchemaDescription(p.Value),
+		DeprecationMessage: getPropertyDeprecationMessage(p.Value),
 	}
 
 	if p.Value.Default != nil && !p.Value.Type.Is(openapi3.TypeArray) {
 		propertySpec.Default = p.Value.Default
 	}
 
-	// Is this property marked as a secret?
-	if isSecret, ok := p.Value.Extensions[ExtSecretProp]; ok {
-		propertySpec.Secret = isSecret.(bool)
-	}
+	propertySpec.Secret = isSecretProperty(p.Value)
 
 	languageName := strings.ToUpper(propName[:1]) + propName[1:]
 	if languageName == ctx.resourceName {
@@ -1167,25 +1564,40 @@ func (ctx *resourceContext) genPropertySpec(propName string, p openapi3.SchemaRe
 
 	typeSpec, _, err := ctx.propertyTypeSpec(propName, p)
 	if err != nil {
-		contract.Failf("Failed to generate type spec (resource: %s, prop %s): %v", ctx.resourceName, propName, err)
+		return propertySpec, errors.Wrapf(err, "generating type spec (resource: %s, prop %s)", ctx.resourceName, propName)
 	}
 
 	propertySpec.TypeSpec = *typeSpec
 
-	return propertySpec
+	return propertySpec, nil
 }
 
 // propertyTypeSpec returns a Pulumi property type spec and
 // a flag that indicates if the type ref was previously
 // encountered.
 func (ctx *resourceContext) propertyTypeSpec(parentName string, propSchema openapi3.SchemaRef) (*pschema.TypeSpec, bool, error) {
+	propSchema = normalizeSchemaRef(propSchema)
+
+	// Files in request schemas are assets, or archives if
+	// the media type says so.
+	if ctx.binaryAsAssets {
+		if typeSpec := getBinaryTypeSpec(propSchema.Value); typeSpec != nil {
+			return typeSpec, false, nil
+		}
+	}
+
 	// References to other type definitions as long as the type is not an array.
-	// Arrays and enums will be handled later in this method.
-	if propSchema.Ref != "" && !propSchema.Value.Type.Is(openapi3.TypeArray) && len(propSchema.Value.Enum) == 0 {
+	// Arrays and enums will be handled later in this method. So are
+	// untyped refs that are only an anyOf union.
+	if propSchema.Ref != "" && !propSchema.Value.Type.Is(openapi3.TypeArray) && len(propSchema.Value.Enum) == 0 && !isUntypedUnion(propSchema.Value) {
 		schemaName := strings.TrimPrefix(propSchema.Ref, componentsSchemaRefPrefix)
 		typName := ToPascalCase(schemaName)
 		typName = sanitizeResourceTitle(typName)
 		tok := fmt.Sprintf("%s:%s:%s", ctx.pkg.Name, ctx.mod, typName)
+		schemaTok := tok
+		if renamedTok, ok := ctx.renamedTypes[schemaTok]; ok {
+			tok = renamedTok
+		}
 
 		typeSchema := propSchema
 
@@ -1197,6 +1609,12 @@ func (ctx *resourceContext) propertyTypeSpec(parentName string, propSchema opena
 			len(typeSchema.Value.Properties) == 0 &&
 			len(typeSchema.Value.OneOf) == 0 &&
 			len(typeSchema.Value.AllOf) == 0 {
+			// Untyped schemas, including OpenAPI 3.1 schemas
+			// whose only type is `null`, can hold any value.
+			if typeSchema.Value.Type.IsEmpty() {
+				return &pschema.TypeSpec{Ref: "pulumi.json#/Any"}, false, nil
+			}
+
 			return &pschema.TypeSpec{
 				Type: typeSchema.Value.Type.Slice()[0],
 			}, false, nil
@@ -1206,20 +1624,35 @@ func (ctx *resourceContext) propertyTypeSpec(parentName string, propSchema opena
 
 		if newType {
 			ctx.visitedTypes.Add(tok)
+			firstRegistered := len(ctx.registeredTypes)
 
+			// The types of component schemas are shared by the
+			// requests and responses, so their files are strings.
+			binaryAsAssets := ctx.binaryAsAssets
+			ctx.binaryAsAssets = false
 			specs, requiredSpecs, err := ctx.genProperties(typName, *typeSchema.Value)
+			ctx.binaryAsAssets = binaryAsAssets
 			if err != nil {
 				return nil, false, errors.Wrapf(err, "generating properties for %s", typName)
 			}
 
-			ctx.pkg.Types[tok] = pschema.ComplexTypeSpec{
+			registeredTok, added := ctx.registerObjectType(getTokenName(tok), pschema.ComplexTypeSpec{
 				ObjectTypeSpec: pschema.ObjectTypeSpec{
 					Description: typeSchema.Value.Description,
 					Type:        typeObject,
 					Properties:  specs,
 					Required:    requiredSpecs.SortedValues(),
 				},
+			})
+			// Another type had the token, so the later refs to
+			// this schema must use the token it was added under.
+			if registeredTok != tok {
+				ctx.visitedTypes.Add(registeredTok)
+				ctx.renamedTypes[schemaTok] = registeredTok
+				ctx.rewriteRenamedTypeRefs(firstRegistered, tok, registeredTok)
+				tok = registeredTok
 			}
+			newType = added
 		}
 
 		referencedTypeName := fmt.Sprintf("#/types/%s", tok)
@@ -1229,22 +1662,21 @@ func (ctx *resourceContext) propertyTypeSpec(parentName string, propSchema opena
 	// Inline properties.
 	if len(propSchema.Value.Properties) > 0 {
 		typName := parentName + "Properties"
-		tok := fmt.Sprintf("%s:%s:%s", ctx.pkg.Name, ctx.mod, typName)
 		specs, requiredSpecs, err := ctx.genProperties(typName, *propSchema.Value)
 		if err != nil {
 			return nil, false, err
 		}
 
-		ctx.pkg.Types[tok] = pschema.ComplexTypeSpec{
+		tok, added := ctx.registerObjectType(typName, pschema.ComplexTypeSpec{
 			ObjectTypeSpec: pschema.ObjectTypeSpec{
 				Description: propSchema.Value.Description,
 				Type:        typeObject,
 				Properties:  specs,
 				Required:    requiredSpecs.SortedValues(),
 			},
-		}
+		})
 		referencedTypeName := fmt.Sprintf("#/types/%s", tok)
-		return &pschema.TypeSpec{Ref: referencedTypeName}, true, nil
+		return &pschema.TypeSpec{Ref: referencedTypeName}, added, nil
 	}
 
 	// Union types.
@@ -1290,20 +1722,18 @@ func (ctx *resourceContext) propertyTypeSpec(parentName string, propSchema opena
 			return nil, false, errors.Wrap(err, "generating properties from allOf schema definition")
 		}
 
-		typName := ToPascalCase(parentName)
-		tok := fmt.Sprintf("%s:%s:%s", ctx.pkg.Name, ctx.mod, typName)
-		ctx.pkg.Types[tok] = pschema.ComplexTypeSpec{
+		tok, added := ctx.registerObjectType(ToPascalCase(parentName), pschema.ComplexTypeSpec{
 			ObjectTypeSpec: pschema.ObjectTypeSpec{
 				Description: propSchema.Value.Description,
 				Type:        typeObject,
 				Properties:  properties,
 				Required:    requiredPropSpecs.SortedValues(),
 			},
-		}
+		})
 
 		return &pschema.TypeSpec{
 			Ref: fmt.Sprintf("#/types/%s", tok),
-		}, true, nil
+		}, added, nil
 	}
 
 	if len(propSchema.Value.Enum) > 0 {
@@ -1367,12 +1797,13 @@ func (ctx *resourceContext) genProperties(parentName string, typeSchema openapi3
 	requiredSpecs := codegen.NewStringSet()
 
 	for _, name := range slices.Sorted(maps.Keys(typeSchema.Properties)) {
-		value := typeSchema.Properties[name]
+		normalized := normalizeSchemaRef(*typeSchema.Properties[name])
+		value := &normalized
 		sdkName := ToSdkName(name)
 
 		if sdkName != name {
-			addNameOverride(sdkName, name, ctx.sdkToAPINameMap)
-			addNameOverride(name, sdkName, ctx.apiToSDKNameMap)
+			ctx.addNameOverride(sdkName, name, ctx.sdkToAPINameMap)
+			ctx.addNameOverride(name, sdkName, ctx.apiToSDKNameMap)
 		}
 
 		var typeSpec *pschema.TypeSpec
@@ -1410,8 +1841,9 @@ func (ctx *resourceContext) genProperties(parentName string, typeSchema openapi3
 		}
 
 		propertySpec := pschema.PropertySpec{
-			Description: value.Value.Description,
-			TypeSpec:    *typeSpec,
+			Description:        getSchemaDescription(value.Value),
+			DeprecationMessage: getPropertyDeprecationMessage(value.Value),
+			TypeSpec:           *typeSpec,
 		}
 
 		// .NET does not allow properties to be the same as the enclosing class - so special case these.
@@ -1430,19 +1862,20 @@ func (ctx *resourceContext) genProperties(parentName string, typeSchema openapi3
 			propertySpec.Default = value.Value.Default
 		}
 
-		// Is this property marked as a secret?
-		if isSecret, ok := value.Value.Extensions[ExtSecretProp]; ok {
-			propertySpec.Secret = isSecret.(bool)
-		}
+		propertySpec.Secret = isSecretProperty(value.Value)
 
 		specs[sdkName] = propertySpec
 	}
 
 	for _, name := range typeSchema.Required {
+		if p, ok := typeSchema.Properties[name]; ok && isNullUnion(p.Value) {
+			continue
+		}
+
 		sdkName := ToSdkName(name)
 		if sdkName != name {
-			addNameOverride(sdkName, name, ctx.sdkToAPINameMap)
-			addNameOverride(name, sdkName, ctx.apiToSDKNameMap)
+			ctx.addNameOverride(sdkName, name, ctx.sdkToAPINameMap)
+			ctx.addNameOverride(name, sdkName, ctx.apiToSDKNameMap)
 		}
 		if _, has := specs[sdkName]; has {
 			requiredSpecs.Add(sdkName)
@@ -1464,7 +1897,7 @@ func (ctx *resourceContext) genPropertiesFromAllOf(parentName string, allOf open
 
 	for _, schemaRef := range allOf {
 		if schemaRef.Ref == "" && !schemaRef.Value.Type.Is(openapi3.TypeObject) {
-			glog.Warningf("Prop type %s uses allOf schema but one of the schema refs is invalid", parentName)
+			ctx.diagnostics.warnf(CodeInvalidAllOf, ctx.pointer, "", "prop type %s uses allOf schema but one of the schema refs is invalid", parentName)
 			continue
 		}
 
@@ -1567,6 +2000,13 @@ func (ctx *resourceContext) genEnumType(enumName string, propSchema openapi3.Sch
 	typName := ToPascalCase(enumName)
 	tok := fmt.Sprintf("%s:%s:%s", ctx.pkg.Name, ctx.mod, typName)
 
+	// OpenAPI 3.1 enums can list `null` as a value and
+	// as a type, neither of which belong in the enum type.
+	propSchema = *normalizeSchemaRef(openapi3.SchemaRef{Value: &propSchema}).Value
+	if !propSchema.Type.IsSingle() {
+		return nil, nil
+	}
+
 	enumSpec := &pschema.ComplexTypeSpec{
 		ObjectTypeSpec: pschema.ObjectTypeSpec{
 			Description: propSchema.Description,
@@ -1584,6 +2024,7 @@ func (ctx *resourceContext) genEnumType(enumName string, propSchema openapi3.Sch
 	default:
 		return nil, errors.Errorf("cannot handle enum values of type %s", propSchema.Type)
 	}
+	setEnumValueDescriptions(enumSpec.Enum, propSchema)
 
 	referencedTypeName := fmt.Sprintf("#/types/%s", tok)
 
@@ -0,0 +1,201 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// normalizeSchemaRef rewrites the OpenAPI 3.1 (JSON Schema 2020-12)
+// constructs of a schema into their OpenAPI 3.0 equivalents so that
+// the rest of the conversion only has to deal with a single type per
+// schema. The schema itself is never mutated. Instead, a shallow copy
+// is returned if anything had to change.
+//
+//   - `null` in a type array, an enum or as a member of a oneOf/anyOf
+//     union makes the schema nullable, which makes the property optional.
+//   - A string `const` becomes a single-value enum. Any other `const`
+//     becomes the default value.
+//   - A type array with multiple types becomes a oneOf union with a
+//     schema for each type.
+//   - `prefixItems`, i.e. tuples, become an array of the union of the
+//     item types, since Pulumi doesn't have tuples.
+func normalizeSchemaRef(schemaRef openapi3.SchemaRef) openapi3.SchemaRef {
+	if schemaRef.Value == nil || !needsNormalization(schemaRef.Value) {
+		return schemaRef
+	}
+
+	s := *schemaRef.Value
+	ref := schemaRef.Ref
+
+	if s.Type.IncludesNull() {
+		types := openapi3.Types(slices.DeleteFunc(slices.Clone(s.Type.Slice()), func(t string) bool {
+			return t == openapi3.TypeNull
+		}))
+		s.Type = &types
+		s.Nullable = true
+	}
+
+	if slices.Contains(s.Enum, nil) {
+		s.Enum = slices.DeleteFunc(slices.Clone(s.Enum), func(v any) bool { return v == nil })
+		s.Nullable = true
+	}
+
+	oneOf, anyOf := withoutNullSchemas(s.OneOf), withoutNullSchemas(s.AnyOf)
+	if len(oneOf) != len(s.OneOf) || len(anyOf) != len(s.AnyOf) {
+		s.OneOf, s.AnyOf = oneOf, anyOf
+		s.Nullable = true
+
+		// `anyOf: [{$ref: ...}, {type: null}]` is the common way
+		// to make a ref nullable, so use the ref itself.
+		if members := append(slices.Clone(oneOf), anyOf...); len(members) == 1 && s.Type.IsEmpty() && len(s.Properties) == 0 && len(s.AllOf) == 0 && s.Discriminator == nil {
+			return normalizeSchemaRef(*members[0])
+		}
+	}
+
+	if s.Const != nil && len(s.Enum) == 0 {
+		if s.Type.IsEmpty() {
+			if t := getConstType(s.Const); t != "" {
+				s.Type = &openapi3.Types{t}
+			}
+		}
+
+		if s.Type.Is(openapi3.TypeString) {
+			s.Enum = []any{s.Const}
+		} else if s.Default == nil {
+			s.Default = s.Const
+		}
+	}
+
+	if s.Type.IsMultiple() {
+		var members openapi3.SchemaRefs
+		for _, t := range s.Type.Slice() {
+			member := s
+			member.Type = &openapi3.Types{t}
+			member.Nullable = false
+			if t != openapi3.TypeArray {
+				member.Items = nil
+				member.PrefixItems = nil
+			}
+			if t != openapi3.TypeObject {
+				member.Properties = nil
+				member.Required = nil
+			}
+			if t != openapi3.TypeString {
+				member.Enum = nil
+			}
+			members = append(members, openapi3.NewSchemaRef("", &member))
+		}
+
+		s.Type = nil
+		s.OneOf = members
+		// The union can't be represented by the named
+		// type of the ref, so it is generated inline.
+		ref = ""
+	}
+
+	if s.Type.Is(openapi3.TypeArray) && s.Items == nil && len(s.PrefixItems) > 0 {
+		items := uniqueSchemaRefs(s.PrefixItems)
+		if len(items) == 1 {
+			s.Items = items[0]
+		} else {
+			s.Items = openapi3.NewSchemaRef("", &openapi3.Schema{OneOf: items})
+		}
+	}
+
+	return openapi3.SchemaRef{Ref: ref, Value: &s}
+}
+
+// needsNormalization returns true if the schema uses any of the
+// OpenAPI 3.1 constructs handled by normalizeSchemaRef.
+func needsNormalization(s *openapi3.Schema) bool {
+	return s.Type.IncludesNull() ||
+		s.Type.IsMultiple() ||
+		s.Const != nil ||
+		len(s.PrefixItems) > 0 ||
+		slices.Contains(s.Enum, nil) ||
+		slices.ContainsFunc(s.OneOf, isNullSchema) ||
+		slices.ContainsFunc(s.AnyOf, isNullSchema)
+}
+
+// isNullUnion returns true if the schema allows `null` values using
+// the OpenAPI 3.1 constructs. Such properties are optional even if
+// they are listed as required, since the API accepts and returns null.
+// The OpenAPI 3.0 `nullable` keyword is deliberately not considered.
+func isNullUnion(s *openapi3.Schema) bool {
+	if s == nil {
+		return false
+	}
+
+	return s.Type.IncludesNull() ||
+		slices.Contains(s.Enum, nil) ||
+		slices.ContainsFunc(s.OneOf, isNullSchema) ||
+		slices.ContainsFunc(s.AnyOf, isNullSchema)
+}
+
+// isUntypedUnion returns true if the schema only has an anyOf union,
+// which is how OpenAPI 3.1 docs commonly define reusable unions.
+func isUntypedUnion(s *openapi3.Schema) bool {
+	return s.Type.IsEmpty() &&
+		len(s.AnyOf) > 0 &&
+		len(s.Properties) == 0 &&
+		len(s.OneOf) == 0 &&
+		len(s.AllOf) == 0
+}
+
+// isNullSchema returns true if the schema only allows `null` values.
+func isNullSchema(schemaRef *openapi3.SchemaRef) bool {
+	return schemaRef != nil && schemaRef.Value != nil && schemaRef.Value.Type.Is(openapi3.TypeNull)
+}
+
+// withoutNullSchemas returns the schemas that aren't null schemas.
+func withoutNullSchemas(schemaRefs openapi3.SchemaRefs) openapi3.SchemaRefs {
+	if !slices.ContainsFunc(schemaRefs, isNullSchema) {
+		return schemaRefs
+	}
+
+	return slices.DeleteFunc(slices.Clone(schemaRefs), isNullSchema)
+}
+
+// uniqueSchemaRefs returns the schemas with the duplicate refs and
+// duplicate single-type inline schemas removed.
+func uniqueSchemaRefs(schemaRefs openapi3.SchemaRefs) openapi3.SchemaRefs {
+	var unique openapi3.SchemaRefs
+	seen := make(map[string]bool)
+	for _, schemaRef := range schemaRefs {
+		key := schemaRef.Ref
+		if key == "" && schemaRef.Value != nil && len(schemaRef.Value.Properties) == 0 && len(schemaRef.Value.Enum) == 0 {
+			key = "type:" + strings.Join(schemaRef.Value.Type.Slice(), ",")
+		}
+
+		if key != "" && seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, schemaRef)
+	}
+
+	return unique
+}
+
+// getConstType returns the JSON Schema type of a const value.
+func getConstType(v any) string {
+	switch c := v.(type) {
+	case string:
+		return openapi3.TypeString
+	case bool:
+		return openapi3.TypeBoolean
+	case float64:
+		if c == float64(int64(c)) {
+			return openapi3.TypeInteger
+		}
+		return openapi3.TypeNumber
+	case int, int64:
+		return openapi3.TypeInteger
+	}
+
+	return ""
+}
@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// TestOpenAPI31Schemas tests that the OpenAPI 3.1 (JSON Schema 2020-12)
+// constructs are converted to their Pulumi schema equivalents.
+func TestOpenAPI31Schemas(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "openapi31_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	_, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	resourceSpec, ok := testPulumiPkg.Resources["fake-package:kettles/v2:Kettle"]
+	assert.Truef(t, ok, "Expected to find a resource called Kettle: %v", testPulumiPkg.Resources)
+
+	t.Run("NullUnion", func(t *testing.T) {
+		assert.Equal(t, "string", resourceSpec.InputProperties["color"].Type)
+		assert.NotContains(t, resourceSpec.RequiredInputs, "color")
+		assert.NotContains(t, resourceSpec.Required, "color")
+		assert.Contains(t, resourceSpec.RequiredInputs, "capacity")
+	})
+
+	t.Run("Const", func(t *testing.T) {
+		kindType := testPulumiPkg.Types[resourceSpec.InputProperties["kind"].Ref[len(typesSchemaRefPrefix):]]
+		assert.Len(t, kindType.Enum, 1)
+		assert.Equal(t, "electric", kindType.Enum[0].Value)
+
+		boils := resourceSpec.InputProperties["boils"]
+		assert.Equal(t, "boolean", boils.Type)
+		assert.Equal(t, true, boils.Default)
+	})
+
+	t.Run("MultipleTypes", func(t *testing.T) {
+		assert.Equal(t, []pschema.TypeSpec{{Type: "string"}, {Type: "integer"}}, resourceSpec.InputProperties["capacity"].OneOf)
+	})
+
+	t.Run("PrefixItems", func(t *testing.T) {
+		dimensions := resourceSpec.InputProperties["dimensions"]
+		assert.Equal(t, "array", dimensions.Type)
+		assert.Equal(t, "number", dimensions.Items.Type)
+
+		label := resourceSpec.InputProperties["label"]
+		assert.Equal(t, "array", label.Type)
+		assert.Equal(t, []pschema.TypeSpec{{Type: "string"}, {Type: "integer"}}, label.Items.OneOf)
+	})
+
+	t.Run("NullableEnum", func(t *testing.T) {
+		sizeType := testPulumiPkg.Types[resourceSpec.InputProperties["size"].Ref[len(typesSchemaRefPrefix):]]
+		assert.Equal(t, []pschema.EnumValueSpec{
+			{Name: "Small", Value: "small"},
+			{Name: "Large", Value: "large"},
+		}, sizeType.Enum)
+	})
+
+	t.Run("UntypedRef", func(t *testing.T) {
+		assert.Equal(t, "pulumi.json#/Any", resourceSpec.InputProperties["metadata"].Ref)
+		assert.Equal(t, "pulumi.json#/Any", resourceSpec.InputProperties["removed"].Ref)
+	})
+
+	t.Run("NullableRef", func(t *testing.T) {
+		assert.Equal(t, "#/types/fake-package:kettles/v2:KettleLid", resourceSpec.InputProperties["lid"].Ref)
+	})
+}
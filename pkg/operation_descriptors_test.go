@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOperationDescriptors tests that each endpoint in the CRUD map
+// has a descriptor of its operation and that the path-only fields
+// are still serialized.
+func TestOperationDescriptors(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "operation_descriptors_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	crudMap, ok := metadata.ResourceCRUDMap["fake-package:lamps/v2:Lamp"]
+	assert.Truef(t, ok, "Expected to find a CRUD map entry for Lamp")
+
+	assert.Equal(t, &OperationDescriptor{
+		Path:               "/v2/lamps",
+		Method:             http.MethodPost,
+		OperationID:        "create_lamp",
+		RequestContentType: jsonMimeType,
+		SuccessStatusCodes: []string{"201", "202"},
+		ResponseSchema:     "lamp",
+	}, crudMap.Operations.C)
+	assert.Equal(t, &OperationDescriptor{
+		Path:               "/v2/lamps/{id}",
+		Method:             http.MethodGet,
+		OperationID:        "get_lamp",
+		SuccessStatusCodes: []string{"200"},
+		ResponseSchema:     "lamp",
+	}, crudMap.Operations.R)
+	assert.Equal(t, &OperationDescriptor{
+		Path:               "/v2/lamps/{id}",
+		Method:             http.MethodPatch,
+		OperationID:        "update_lamp",
+		RequestContentType: jsonMimeType,
+		SuccessStatusCodes: []string{"200"},
+	}, crudMap.Operations.U)
+	assert.Equal(t, &OperationDescriptor{
+		Path:               "/v2/lamps/{id}",
+		Method:             http.MethodDelete,
+		OperationID:        "delete_lamp",
+		SuccessStatusCodes: []string{"204"},
+	}, crudMap.Operations.D)
+	assert.Nil(t, crudMap.Operations.P)
+
+	t.Run("BackwardCompatibleJSON", func(t *testing.T) {
+		b, err := json.Marshal(crudMap)
+		assert.Nil(t, err)
+
+		var m map[string]interface{}
+		assert.Nil(t, json.Unmarshal(b, &m))
+		assert.Equal(t, "/v2/lamps", m["c"])
+		assert.Equal(t, "/v2/lamps/{id}", m["r"])
+		assert.Equal(t, "/v2/lamps/{id}", m["u"])
+		assert.Equal(t, "/v2/lamps/{id}", m["d"])
+		assert.Contains(t, m, "operations")
+	})
+}
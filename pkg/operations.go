@@ -0,0 +1,80 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// newOperationDescriptor returns the descriptor of the operation
+// for the HTTP method on the API path. Returns nil if the path
+// doesn't have an operation for the method.
+func (o *OpenAPIContext) newOperationDescriptor(apiPath, method string) *OperationDescriptor {
+	pathItem := o.Doc.Paths.Find(apiPath)
+	if pathItem == nil {
+		return nil
+	}
+
+	op := pathItem.GetOperation(method)
+	if op == nil {
+		return nil
+	}
+
+	desc := &OperationDescriptor{
+		Path:        apiPath,
+		Method:      method,
+		OperationID: op.OperationID,
+	}
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		desc.RequestContentType = getPreferredContentType(op.RequestBody.Value.Content)
+	}
+
+	if op.Responses == nil {
+		return desc
+	}
+
+	for _, code := range slices.Sorted(maps.Keys(op.Responses.Map())) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+
+		desc.SuccessStatusCodes = append(desc.SuccessStatusCodes, code)
+
+		// The response schema is taken from the first
+		// success response that has one.
+		resp := op.Responses.Value(code)
+		if desc.ResponseSchema != "" || resp == nil || resp.Value == nil {
+			continue
+		}
+
+		mediaType := resp.Value.Content.Get(getPreferredContentType(resp.Value.Content))
+		if mediaType != nil && mediaType.Schema != nil {
+			desc.ResponseSchema = strings.TrimPrefix(mediaType.Schema.Ref, componentsSchemaRefPrefix)
+		}
+	}
+
+	return desc
+}
+
+// getPreferredContentType returns the JSON content type if it is
+// one of the content types, including through a wildcard such as
+// `*/*`, then the multipart/form-data one, otherwise the first one
+// in sorted order.
+func getPreferredContentType(content openapi3.Content) string {
+	if len(content) == 0 {
+		return ""
+	}
+
+	for _, contentType := range []string{jsonMimeType, multipartFormDataMimeType} {
+		if content.Get(contentType) != nil {
+			return contentType
+		}
+	}
+
+	return slices.Sorted(maps.Keys(content))[0]
+}
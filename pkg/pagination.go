@@ -0,0 +1,245 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+)
+
+// maxPaginationPropertyDepth is how deep the response schema of a
+// list endpoint is searched for the next link and cursor properties.
+// DigitalOcean, for example, returns the next link in `links.pages.next`.
+const maxPaginationPropertyDepth = 3
+
+// The names are compared case-insensitively and without `_` and `-`.
+var (
+	nextLinkPropertyNames = codegen.NewStringSet("nextlink", "@odata.nextlink", "next", "nexturl", "nextpageurl", "nextpage")
+	cursorPropertyNames   = codegen.NewStringSet("nextpagetoken", "nexttoken", "nextcursor", "cursor", "continuationtoken", "nextmarker")
+	cursorParamNames      = codegen.NewStringSet("pagetoken", "nextpagetoken", "nexttoken", "cursor", "continuationtoken", "after", "startingafter", "marker")
+	offsetParamNames      = codegen.NewStringSet("offset", "skip", "$skip", "start")
+	limitParamNames       = codegen.NewStringSet("limit", "perpage", "pagesize", "maxresults", "top", "$top", "count")
+	itemsPropertyNames    = []string{"items", "data", "value", "results"}
+)
+
+// normalizePaginationName returns the name used to compare property
+// and param names with the well-known pagination names.
+func normalizePaginationName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// validatePagination checks the Pagination options.
+func (o *OpenAPIContext) validatePagination() error {
+	for _, operationID := range slices.Sorted(maps.Keys(o.Pagination)) {
+		p := o.Pagination[operationID]
+		if p == nil {
+			return errors.Errorf("pagination for operation %s must not be nil", operationID)
+		}
+		if err := p.validate(); err != nil {
+			return errors.Wrapf(err, "invalid pagination for operation %s", operationID)
+		}
+	}
+
+	return nil
+}
+
+// appendPropertyPath appends the name of a property to a property
+// path. Names with a `.`, such as `@odata.nextLink`, or brackets are
+// quoted in brackets, e.g. `["@odata.nextLink"]`, so that the path
+// is unambiguous.
+func appendPropertyPath(path, name string) string {
+	if strings.ContainsAny(name, `.[]"`) {
+		return path + "[" + strconv.Quote(name) + "]"
+	}
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// gatherPagination records the pagination scheme of a list endpoint.
+// The Pagination option for the operation takes precedence over the
+// detected scheme.
+func (o *OpenAPIContext) gatherPagination(funcTypeToken string, pathItem openapi3.PathItem, respSchema *openapi3.SchemaRef) {
+	op := pathItem.Get
+	if override, ok := o.Pagination[op.OperationID]; ok {
+		if override.Scheme != PaginationSchemeNone {
+			o.paginationMap[funcTypeToken] = override
+		}
+		return
+	}
+
+	params := append(slices.Clone(pathItem.Parameters), op.Parameters...)
+	if p := detectPagination(op, params, respSchema); p != nil {
+		o.paginationMap[funcTypeToken] = p
+	}
+}
+
+// detectPagination returns the pagination scheme of a list endpoint
+// or nil if the endpoint doesn't look paginated. Next links take
+// precedence over cursors, which take precedence over offsets.
+func detectPagination(op *openapi3.Operation, params openapi3.Parameters, respSchema *openapi3.SchemaRef) *Pagination {
+	var queryParams []string
+	for _, param := range params {
+		if param.Value.In == parameterLocationQuery {
+			queryParams = append(queryParams, param.Value.Name)
+		}
+	}
+	findQueryParam := func(names codegen.StringSet) string {
+		for _, p := range queryParams {
+			if names.Has(normalizePaginationName(p)) {
+				return p
+			}
+		}
+		return ""
+	}
+
+	var itemsProperty, nextLinkProperty, cursorProperty string
+	if respSchema != nil && respSchema.Value != nil && !respSchema.Value.Type.Is(openapi3.TypeArray) {
+		props := getAPISchemaProperties(respSchema.Value)
+		if name := getItemsProperty(props); name != "" {
+			itemsProperty = appendPropertyPath("", name)
+		}
+		nextLinkProperty = findPaginationProperty("", props, nextLinkPropertyNames, 1)
+		cursorProperty = findPaginationProperty("", props, cursorPropertyNames, 1)
+	}
+
+	if nextLinkProperty != "" {
+		return &Pagination{
+			Scheme:           PaginationSchemeNextLink,
+			ItemsProperty:    itemsProperty,
+			NextLinkProperty: nextLinkProperty,
+		}
+	}
+
+	if hasLinkHeader(op) {
+		return &Pagination{
+			Scheme:         PaginationSchemeNextLink,
+			ItemsProperty:  itemsProperty,
+			NextLinkHeader: "Link",
+		}
+	}
+
+	if cursorParam := findQueryParam(cursorParamNames); cursorProperty != "" && cursorParam != "" {
+		return &Pagination{
+			Scheme:         PaginationSchemeCursor,
+			ItemsProperty:  itemsProperty,
+			CursorProperty: cursorProperty,
+			CursorParam:    cursorParam,
+		}
+	}
+
+	if offsetParam := findQueryParam(offsetParamNames); offsetParam != "" {
+		return &Pagination{
+			Scheme:        PaginationSchemeOffset,
+			ItemsProperty: itemsProperty,
+			OffsetParam:   offsetParam,
+			LimitParam:    findQueryParam(limitParamNames),
+		}
+	}
+
+	return nil
+}
+
+// findPaginationProperty returns the path of the first string property,
+// in sorted order, whose name is one of names. Nested objects are
+// searched after the top-level properties.
+func findPaginationProperty(path string, props map[string]*openapi3.SchemaRef, names codegen.StringSet, depth int) string {
+	sortedNames := slices.Sorted(maps.Keys(props))
+	for _, name := range sortedNames {
+		prop := props[name]
+		if prop.Value != nil && prop.Value.Type.Is(openapi3.TypeString) && names.Has(normalizePaginationName(name)) {
+			return appendPropertyPath(path, name)
+		}
+	}
+
+	if depth >= maxPaginationPropertyDepth {
+		return ""
+	}
+
+	for _, name := range sortedNames {
+		prop := props[name]
+		if prop.Value == nil || !prop.Value.Type.Is(openapi3.TypeObject) {
+			continue
+		}
+
+		if propPath := findPaginationProperty(appendPropertyPath(path, name), getAPISchemaProperties(prop.Value), names, depth+1); propPath != "" {
+			return propPath
+		}
+	}
+
+	return ""
+}
+
+// getItemsProperty returns the name of the array property with the
+// items of a page. Well-known names are preferred over the first
+// array property in sorted order.
+func getItemsProperty(props map[string]*openapi3.SchemaRef) string {
+	isArray := func(name string) bool {
+		prop, ok := props[name]
+		return ok && prop.Value != nil && prop.Value.Type.Is(openapi3.TypeArray)
+	}
+
+	for _, name := range itemsPropertyNames {
+		if isArray(name) {
+			return name
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		if isArray(name) {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// hasLinkHeader returns true if the success response of an operation
+// has a `Link` header, as described in RFC 8288.
+func hasLinkHeader(op *openapi3.Operation) bool {
+	resp := op.Responses.Status(200)
+	if resp == nil || resp.Value == nil {
+		return false
+	}
+
+	for name := range resp.Value.Headers {
+		if strings.EqualFold(name, "Link") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validate checks that the pagination has the properties and params
+// required by its scheme.
+func (p *Pagination) validate() error {
+	switch p.Scheme {
+	case PaginationSchemeNextLink:
+		if p.NextLinkProperty == "" && p.NextLinkHeader == "" {
+			return errors.New("nextLink pagination requires nextLinkProperty or nextLinkHeader")
+		}
+	case PaginationSchemeCursor:
+		if p.CursorProperty == "" || p.CursorParam == "" {
+			return errors.New("cursor pagination requires cursorProperty and cursorParam")
+		}
+	case PaginationSchemeOffset:
+		if p.OffsetParam == "" {
+			return errors.New("offset pagination requires offsetParam")
+		}
+	case PaginationSchemeNone:
+	default:
+		return errors.Errorf("unknown scheme %q", p.Scheme)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPagination tests that the pagination scheme of list endpoints
+// is detected and can be overridden.
+func TestPagination(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "pagination_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	t.Run("NextLinkProperty", func(t *testing.T) {
+		assert.Equal(t, &Pagination{
+			Scheme:           PaginationSchemeNextLink,
+			ItemsProperty:    "ferrets",
+			NextLinkProperty: "links.pages.next",
+		}, metadata.PaginationMap["fake-package:ferrets/v2:listFerrets"])
+	})
+
+	t.Run("NextLinkPropertyWithDot", func(t *testing.T) {
+		assert.Equal(t, &Pagination{
+			Scheme:           PaginationSchemeNextLink,
+			ItemsProperty:    "value",
+			NextLinkProperty: `["@odata.nextLink"]`,
+		}, metadata.PaginationMap["fake-package:toads/v2:listToads"])
+	})
+
+	t.Run("NextLinkHeader", func(t *testing.T) {
+		assert.Equal(t, &Pagination{
+			Scheme:         PaginationSchemeNextLink,
+			NextLinkHeader: "Link",
+		}, metadata.PaginationMap["fake-package:geckos/v2:listGeckos"])
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		assert.Equal(t, &Pagination{
+			Scheme:         PaginationSchemeCursor,
+			ItemsProperty:  "data",
+			CursorProperty: "next_page_token",
+			CursorParam:    "page_token",
+		}, metadata.PaginationMap["fake-package:owls/v2:listOwls"])
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		assert.Equal(t, &Pagination{
+			Scheme:      PaginationSchemeOffset,
+			OffsetParam: "offset",
+			LimitParam:  "limit",
+		}, metadata.PaginationMap["fake-package:carps/v2:listCarps"])
+	})
+
+	t.Run("NotPaginated", func(t *testing.T) {
+		assert.Contains(t, testPulumiPkg.Functions, "fake-package:moles/v2:listMoles")
+		assert.NotContains(t, metadata.PaginationMap, "fake-package:moles/v2:listMoles")
+		assert.NotContains(t, metadata.PaginationMap, "fake-package:newts/v2:listNewts")
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		newtsPagination := &Pagination{
+			Scheme:         PaginationSchemeCursor,
+			ItemsProperty:  "entries",
+			CursorProperty: "until",
+			CursorParam:    "from",
+		}
+		openAPICtx := &OpenAPIContext{
+			Doc: *testOpenAPIDoc,
+			Pkg: &testPulumiPkg,
+			Pagination: map[string]*Pagination{
+				"list_newts": newtsPagination,
+				"list_carps": {Scheme: PaginationSchemeNone},
+			},
+		}
+
+		metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+		assert.Nil(t, err)
+
+		assert.Equal(t, newtsPagination, metadata.PaginationMap["fake-package:newts/v2:listNewts"])
+		assert.NotContains(t, metadata.PaginationMap, "fake-package:carps/v2:listCarps")
+	})
+
+	t.Run("InvalidOverride", func(t *testing.T) {
+		openAPICtx := &OpenAPIContext{
+			Doc: *testOpenAPIDoc,
+			Pkg: &testPulumiPkg,
+			Pagination: map[string]*Pagination{
+				"list_newts": {Scheme: PaginationSchemeCursor, CursorProperty: "until"},
+			},
+		}
+
+		_, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+		assert.ErrorContains(t, err, "invalid pagination for operation list_newts")
+	})
+}
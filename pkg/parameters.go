@@ -0,0 +1,353 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// HeaderParamTarget is where the value of a header param comes from.
+type HeaderParamTarget string
+
+const (
+	// HeaderParamTargetProvider maps a header param to a provider config
+	// variable and input so that it is sent with every request.
+	HeaderParamTargetProvider HeaderParamTarget = "provider"
+	// HeaderParamTargetInput maps a header param to an input of the
+	// resources and functions whose operations declare it.
+	HeaderParamTargetInput HeaderParamTarget = "input"
+	// HeaderParamTargetIgnore skips a header param.
+	HeaderParamTargetIgnore HeaderParamTarget = "ignore"
+)
+
+// reservedHeaderParams are the header params that the OpenAPI
+// spec says must be ignored.
+var reservedHeaderParams = codegen.NewStringSet("accept", "content-type", "authorization")
+
+// genFunctionInputs returns the input properties of a get/list function
+// and the names of the required ones from the parameters of its GET
+// endpoint.
+//
+// Path params are always required string inputs. Query params are typed
+// inputs that are only required if the param is, and their wire names
+// are recorded in the queryParamsMap so that a provider can build the
+// query string. Header params that aren't mapped to the provider are
+// inputs too, and are recorded in the headerParamsMap.
+func (o *OpenAPIContext) genFunctionInputs(ctx *resourceContext, funcTypeToken string, parameters openapi3.Parameters) (map[string]pschema.PropertySpec, codegen.StringSet) {
+	requiredInputs := codegen.NewStringSet()
+	inputProps := make(map[string]pschema.PropertySpec)
+
+	for _, param := range parameters {
+		if param.Value.In != parameterLocationPath {
+			continue
+		}
+
+		paramName := param.Value.Name
+		sdkName := ToSdkName(paramName)
+
+		if sdkName != paramName {
+			ctx.addNameOverride(sdkName, paramName, o.sdkToAPINameMap)
+			ctx.addNameOverride(paramName, sdkName, o.apiToSDKNameMap)
+			ctx.addNameOverride(paramName, sdkName, o.pathParamNameMap)
+		}
+
+		inputProps[sdkName] = pschema.PropertySpec{
+			Description:        param.Value.Description,
+			DeprecationMessage: getDeprecationMessage("property", param.Value.Deprecated, param.Value.Description, param.Value.Extensions),
+			TypeSpec:           pschema.TypeSpec{Type: typeString},
+		}
+		requiredInputs.Add(sdkName)
+	}
+
+	for _, param := range parameters {
+		var paramsMap map[string]map[string]string
+		switch {
+		case param.Value.In == parameterLocationQuery:
+			paramsMap = o.queryParamsMap
+		case param.Value.In == parameterLocationHeader && o.getHeaderParamTarget(param.Value.Name) == HeaderParamTargetInput:
+			paramsMap = o.headerParamsMap
+		default:
+			continue
+		}
+
+		sdkName, propSpec, ok := o.genParamPropertySpec(ctx, funcTypeToken, param.Value, inputProps)
+		if !ok {
+			continue
+		}
+
+		inputProps[sdkName] = propSpec
+		if param.Value.Required {
+			requiredInputs.Add(sdkName)
+		}
+
+		if _, ok := paramsMap[funcTypeToken]; !ok {
+			paramsMap[funcTypeToken] = make(map[string]string)
+		}
+		paramsMap[funcTypeToken][sdkName] = param.Value.Name
+	}
+
+	return inputProps, requiredInputs
+}
+
+// genParamPropertySpec returns the SDK name and the property spec
+// of the input for a query or header param. Returns false if the
+// param can't be mapped to an input, in which case the problem is
+// recorded as a diagnostic.
+func (o *OpenAPIContext) genParamPropertySpec(ctx *resourceContext, token string, param *openapi3.Parameter, existingProps map[string]pschema.PropertySpec) (string, pschema.PropertySpec, bool) {
+	paramName := param.Name
+	sdkName := getParamSdkName(param)
+	if sdkName == "" {
+		ctx.diagnostics.warnf(CodeUnsupportedSchema, ctx.pointer, token, "%s param %q has no valid input name and was skipped", param.In, paramName)
+		return "", pschema.PropertySpec{}, false
+	}
+	if _, ok := existingProps[sdkName]; ok {
+		ctx.diagnostics.warnf(CodeNameOverrideConflict, ctx.pointer, token, "%s param %s conflicts with the input %s and was skipped", param.In, paramName, sdkName)
+		return "", pschema.PropertySpec{}, false
+	}
+
+	if param.Schema == nil || param.Schema.Value == nil {
+		ctx.diagnostics.warnf(CodeUnsupportedSchema, ctx.pointer, token, "%s param %s has no schema and was skipped", param.In, paramName)
+		return "", pschema.PropertySpec{}, false
+	}
+
+	propSpec, err := ctx.genPropertySpec(sdkName, *param.Schema)
+	if err != nil {
+		ctx.diagnostics.errorf(CodeUnsupportedSchema, ctx.pointer, token, "generating input for %s param %s: %v", param.In, paramName, err)
+		return "", pschema.PropertySpec{}, false
+	}
+	if param.Description != "" {
+		propSpec.Description = param.Description
+	}
+	if msg := getDeprecationMessage("property", param.Deprecated, param.Description, param.Extensions); msg != "" {
+		propSpec.DeprecationMessage = msg
+	}
+
+	return sdkName, propSpec, true
+}
+
+// getHeaderParamTarget returns where the value of a header param
+// comes from. HeaderParams takes precedence over the default, which
+// is the provider for header params declared by every operation.
+func (o *OpenAPIContext) getHeaderParamTarget(name string) HeaderParamTarget {
+	for _, header := range slices.Sorted(maps.Keys(o.HeaderParams)) {
+		if strings.EqualFold(header, name) {
+			return o.HeaderParams[header]
+		}
+	}
+
+	if reservedHeaderParams.Has(strings.ToLower(name)) {
+		return HeaderParamTargetIgnore
+	}
+
+	if o.providerHeaderParams.Has(strings.ToLower(name)) {
+		return HeaderParamTargetProvider
+	}
+
+	return HeaderParamTargetInput
+}
+
+// forEachOperation calls fn for each operation in the API that
+// isn't excluded, in a deterministic order, with the path and
+// operation-level params of the operation.
+func (o *OpenAPIContext) forEachOperation(fn func(apiPath, method string, op *openapi3.Operation, params openapi3.Parameters)) {
+	for _, apiPath := range o.Doc.Paths.InMatchingOrder() {
+		pathItem := o.Doc.Paths.Find(apiPath)
+		ops := pathItem.Operations()
+		for _, method := range slices.Sorted(maps.Keys(ops)) {
+			if o.exclusionEvaluator.ShouldExclude(method, apiPath) {
+				continue
+			}
+
+			op := ops[method]
+			params := append(slices.Clone(pathItem.Parameters), op.Parameters...)
+			fn(apiPath, method, op, params)
+		}
+	}
+}
+
+// gatherProviderHeaderParams adds a provider config variable and input
+// for each header param mapped to the provider. Header params that are
+// declared by every operation are mapped to the provider by default.
+func (o *OpenAPIContext) gatherProviderHeaderParams() {
+	numOps := 0
+	headerCounts := make(map[string]int)
+	o.forEachOperation(func(_, _ string, _ *openapi3.Operation, params openapi3.Parameters) {
+		numOps++
+		seen := codegen.NewStringSet()
+		for _, param := range params {
+			name := strings.ToLower(param.Value.Name)
+			if param.Value.In != parameterLocationHeader || seen.Has(name) || getParamSdkName(param.Value) == "" {
+				continue
+			}
+			seen.Add(name)
+			headerCounts[name]++
+		}
+	})
+
+	for name, count := range headerCounts {
+		if count == numOps {
+			o.providerHeaderParams.Add(name)
+		}
+	}
+
+	cloned := false
+	o.forEachOperation(func(apiPath, method string, _ *openapi3.Operation, params openapi3.Parameters) {
+		for _, param := range params {
+			if param.Value.In != parameterLocationHeader || o.getHeaderParamTarget(param.Value.Name) != HeaderParamTargetProvider {
+				continue
+			}
+
+			sdkName := getParamSdkName(param.Value)
+			if sdkName == "" {
+				o.diagnostics.warnf(CodeUnsupportedSchema, operationPointer(apiPath, method), "", "header param %q has no valid input name and was skipped", param.Value.Name)
+				continue
+			}
+			if _, ok := o.providerHeaderParamsMap[sdkName]; ok {
+				continue
+			}
+
+			propSpec := pschema.PropertySpec{
+				Description:        param.Value.Description,
+				DeprecationMessage: getDeprecationMessage("property", param.Value.Deprecated, param.Value.Description, param.Value.Extensions),
+				TypeSpec:           pschema.TypeSpec{Type: getPrimitiveType(param.Value.Schema)},
+			}
+			if param.Value.Schema != nil && param.Value.Schema.Value != nil {
+				if isSecret, ok := param.Value.Schema.Value.Extensions[ExtSecretProp].(bool); ok {
+					propSpec.Secret = isSecret
+				}
+			}
+
+			if !cloned {
+				o.cloneProviderSpecs()
+				cloned = true
+			}
+
+			// Config variables and provider inputs that are
+			// already in the package spec are left as-is so
+			// that they can be customized, for example, with
+			// a default value from an environment variable.
+			if _, ok := o.Pkg.Config.Variables[sdkName]; !ok {
+				o.Pkg.Config.Variables[sdkName] = propSpec
+			}
+			if _, ok := o.Pkg.Provider.InputProperties[sdkName]; !ok {
+				o.Pkg.Provider.InputProperties[sdkName] = propSpec
+			}
+
+			o.providerHeaderParamsMap[sdkName] = param.Value.Name
+		}
+	})
+}
+
+// cloneProviderSpecs replaces the config variables and the provider spec
+// of the package with copies, so that adding the header params to them
+// doesn't mutate the maps of the caller, which may be shared with other
+// package specs.
+func (o *OpenAPIContext) cloneProviderSpecs() {
+	o.Pkg.Config.Variables = maps.Clone(o.Pkg.Config.Variables)
+	if o.Pkg.Config.Variables == nil {
+		o.Pkg.Config.Variables = make(map[string]pschema.PropertySpec)
+	}
+
+	provider := pschema.ResourceSpec{}
+	if o.Pkg.Provider != nil {
+		provider = *o.Pkg.Provider
+	}
+	provider.InputProperties = maps.Clone(provider.InputProperties)
+	if provider.InputProperties == nil {
+		provider.InputProperties = make(map[string]pschema.PropertySpec)
+	}
+	o.Pkg.Provider = &provider
+}
+
+// gatherResourceHeaderParams adds an input to each resource for the
+// header params of its CRUD operations that aren't mapped to the
+// provider. The input is required if any of the operations requires
+// the header param.
+func (o *OpenAPIContext) gatherResourceHeaderParams() {
+	for _, tok := range slices.Sorted(maps.Keys(o.resourceCRUDMap)) {
+		resourceSpec, ok := o.Pkg.Resources[tok]
+		if !ok {
+			continue
+		}
+
+		tokParts := strings.Split(tok, ":")
+		requiredInputs := codegen.NewStringSet(resourceSpec.RequiredInputs...)
+		ops := o.resourceCRUDMap[tok].Operations
+		for _, desc := range []*OperationDescriptor{ops.C, ops.R, ops.U, ops.D, ops.P} {
+			if desc == nil {
+				continue
+			}
+
+			pathItem := o.Doc.Paths.Find(desc.Path)
+			params := append(slices.Clone(pathItem.Parameters), pathItem.GetOperation(desc.Method).Parameters...)
+			ctx := o.newResourceContext(tokParts[1], tokParts[2], operationPointer(desc.Path, desc.Method))
+			for _, param := range params {
+				if param.Value.In != parameterLocationHeader || o.getHeaderParamTarget(param.Value.Name) != HeaderParamTargetInput {
+					continue
+				}
+
+				// The header param may have been added
+				// already for another operation.
+				sdkName := getParamSdkName(param.Value)
+				if _, ok := o.headerParamsMap[tok][sdkName]; !ok {
+					var propSpec pschema.PropertySpec
+					sdkName, propSpec, ok = o.genParamPropertySpec(ctx, tok, param.Value, resourceSpec.InputProperties)
+					if !ok {
+						continue
+					}
+					if resourceSpec.InputProperties == nil {
+						resourceSpec.InputProperties = make(map[string]pschema.PropertySpec)
+					}
+					resourceSpec.InputProperties[sdkName] = propSpec
+
+					if _, ok := o.headerParamsMap[tok]; !ok {
+						o.headerParamsMap[tok] = make(map[string]string)
+					}
+					o.headerParamsMap[tok][sdkName] = param.Value.Name
+				}
+
+				if param.Value.Required {
+					requiredInputs.Add(sdkName)
+				}
+			}
+		}
+
+		resourceSpec.RequiredInputs = requiredInputs.SortedValues()
+		o.Pkg.Resources[tok] = resourceSpec
+	}
+}
+
+// getParamSdkName returns the name of the input for a query or header
+// param. Header names are usually in Train-Case, so the first letter of
+// their SDK names is lowercased to keep the input names in camelCase.
+// Returns an empty string if the param name has no valid characters.
+func getParamSdkName(param *openapi3.Parameter) string {
+	sdkName := ToSdkName(param.Name)
+	if param.In == parameterLocationHeader && sdkName != "" {
+		return strings.ToLower(sdkName[:1]) + sdkName[1:]
+	}
+	return sdkName
+}
+
+// getPrimitiveType returns the Pulumi primitive type of a param
+// schema, or string if the schema isn't of a primitive type.
+func getPrimitiveType(schemaRef *openapi3.SchemaRef) string {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return typeString
+	}
+
+	for _, t := range []string{openapi3.TypeBoolean, openapi3.TypeInteger, openapi3.TypeNumber} {
+		if schemaRef.Value.Type.Is(t) {
+			return t
+		}
+	}
+
+	return typeString
+}
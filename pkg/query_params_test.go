@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestQueryParams tests that the query params of GET endpoints
+// are mapped to typed inputs of the get/list functions.
+func TestQueryParams(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "query_params_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	t.Run("ListFunction", func(t *testing.T) {
+		funcToken := "fake-package:bikes/v2:listBikes"
+		funcSpec, ok := testPulumiPkg.Functions[funcToken]
+		assert.Truef(t, ok, "Expected to find a function called listBikes: %v", testPulumiPkg.Functions)
+		if !ok {
+			return
+		}
+
+		inputs := funcSpec.Inputs.Properties
+		assert.Equal(t, "string", inputs["region"].Type)
+		assert.Equal(t, "Only return bikes in this region.", inputs["region"].Description)
+		assert.Equal(t, "array", inputs["tag"].Type)
+		assert.Equal(t, "string", inputs["tag"].Items.Type)
+		assert.Equal(t, "integer", inputs["minGears"].Type)
+		assert.Equal(t, []string{"minGears"}, funcSpec.Inputs.Required)
+
+		assert.Equal(t, map[string]string{
+			"region":   "region",
+			"tag":      "tag",
+			"minGears": "min_gears",
+		}, metadata.QueryParamsMap[funcToken])
+	})
+
+	t.Run("GetFunction", func(t *testing.T) {
+		funcToken := "fake-package:bikes/v2:getBike"
+		funcSpec, ok := testPulumiPkg.Functions[funcToken]
+		assert.Truef(t, ok, "Expected to find a function called getBike: %v", testPulumiPkg.Functions)
+		if !ok {
+			return
+		}
+
+		inputs := funcSpec.Inputs.Properties
+		assert.Equal(t, "string", inputs["bikeId"].Type)
+		assert.Equal(t, "boolean", inputs["includeParts"].Type)
+		assert.Equal(t, []string{"bikeId"}, funcSpec.Inputs.Required)
+
+		assert.Equal(t, map[string]string{
+			"includeParts": "include_parts",
+		}, metadata.QueryParamsMap[funcToken])
+	})
+}
@@ -0,0 +1,164 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// gatherReplaceOnChanges determines which input properties of the resources
+// gathered from the API can be updated in-place and which ones force the
+// resource to be replaced.
+//
+//   - Resources that have a PUT endpoint can be updated entirely in-place.
+//   - Resources that have a PATCH endpoint can only update the properties
+//     present in the PATCH request body. The rest force a replacement.
+//   - Resources that have neither must be replaced on any change.
+//
+// Top-level input properties that force a replacement are marked with
+// ReplaceOnChanges in the schema. Object types are shared between
+// resources, so the paths of the nested properties are only recorded
+// in the provider metadata.
+func (o *OpenAPIContext) gatherReplaceOnChanges() {
+	for _, tok := range slices.Sorted(maps.Keys(o.resourceCRUDMap)) {
+		crudMap := o.resourceCRUDMap[tok]
+		resourceSpec, ok := o.Pkg.Resources[tok]
+		if !ok || crudMap.C == nil || crudMap.P != nil {
+			continue
+		}
+
+		// The inputs for header params are sent with every request
+		// instead of in the request body, so they never force a
+		// replacement.
+		inputs := maps.Clone(resourceSpec.InputProperties)
+		maps.DeleteFunc(inputs, func(name string, _ pschema.PropertySpec) bool {
+			_, ok := o.headerParamsMap[tok][name]
+			return ok
+		})
+
+		var replaceOnChanges []string
+		if crudMap.U == nil {
+			replaceOnChanges = slices.Sorted(maps.Keys(inputs))
+		} else {
+			patchProps := getSchemaProperties(o.patchRequestSchemas[tok])
+
+			var patchable []string
+			for _, name := range slices.Sorted(maps.Keys(inputs)) {
+				if _, ok := patchProps[name]; ok {
+					patchable = append(patchable, name)
+				}
+			}
+			o.patchablePropertiesMap[tok] = patchable
+
+			replaceOnChanges = o.getReplaceOnChangesPaths("", inputs, patchProps, codegen.NewStringSet())
+		}
+
+		if len(replaceOnChanges) == 0 {
+			continue
+		}
+		o.replaceOnChangesMap[tok] = replaceOnChanges
+
+		for _, p := range replaceOnChanges {
+			if strings.ContainsAny(p, ".[") {
+				continue
+			}
+
+			inputProp := resourceSpec.InputProperties[p]
+			inputProp.ReplaceOnChanges = true
+			resourceSpec.InputProperties[p] = inputProp
+
+			if prop, ok := resourceSpec.Properties[p]; ok {
+				prop.ReplaceOnChanges = true
+				resourceSpec.Properties[p] = prop
+			}
+		}
+		o.Pkg.Resources[tok] = resourceSpec
+	}
+}
+
+// getReplaceOnChangesPaths returns the paths of the properties in props,
+// including nested ones, that are missing from patchProps. Array items
+// are denoted with `[*]` in the paths.
+func (o *OpenAPIContext) getReplaceOnChangesPaths(prefix string, props map[string]pschema.PropertySpec, patchProps map[string]*openapi3.SchemaRef, visitedTypes codegen.StringSet) []string {
+	var paths []string
+
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		patchProp, ok := patchProps[name]
+		if !ok {
+			paths = append(paths, prefix+name)
+			continue
+		}
+
+		typeSpec := props[name].TypeSpec
+		patchSchema := patchProp.Value
+		path := prefix + name
+		if typeSpec.Items != nil {
+			typeSpec = *typeSpec.Items
+			path += "[*]"
+			if patchSchema.Items == nil {
+				continue
+			}
+			patchSchema = patchSchema.Items.Value
+		}
+
+		if !strings.HasPrefix(typeSpec.Ref, typesSchemaRefPrefix) {
+			continue
+		}
+
+		// Free-form objects in the PATCH request body
+		// allow any of their properties to be patched.
+		nestedPatchProps := getSchemaProperties(patchSchema)
+		typeTok := strings.TrimPrefix(typeSpec.Ref, typesSchemaRefPrefix)
+		objectType, ok := o.Pkg.Types[typeTok]
+		if !ok || len(nestedPatchProps) == 0 || visitedTypes.Has(typeTok) {
+			continue
+		}
+
+		visitedTypes.Add(typeTok)
+		paths = append(paths, o.getReplaceOnChangesPaths(path+".", objectType.Properties, nestedPatchProps, visitedTypes)...)
+		visitedTypes.Delete(typeTok)
+	}
+
+	return paths
+}
+
+// getSchemaProperties returns the properties of a schema, including
+// the ones from its allOf, oneOf and anyOf schemas, keyed by their
+// SDK names.
+func getSchemaProperties(schema *openapi3.Schema) map[string]*openapi3.SchemaRef {
+	props := make(map[string]*openapi3.SchemaRef)
+	for name, prop := range getAPISchemaProperties(schema) {
+		props[ToSdkName(name)] = prop
+	}
+	return props
+}
+
+// getAPISchemaProperties returns the properties of a schema, including
+// the ones from its allOf, oneOf and anyOf schemas, keyed by their API
+// names.
+func getAPISchemaProperties(schema *openapi3.Schema) map[string]*openapi3.SchemaRef {
+	props := make(map[string]*openapi3.SchemaRef)
+	if schema == nil {
+		return props
+	}
+
+	maps.Copy(props, schema.Properties)
+
+	for _, schemaRefs := range []openapi3.SchemaRefs{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for _, schemaRef := range schemaRefs {
+			if schemaRef == nil {
+				continue
+			}
+			maps.Copy(props, getAPISchemaProperties(schemaRef.Value))
+		}
+	}
+
+	return props
+}
@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReplaceOnChanges tests that the input properties missing
+// from the PATCH request body of a resource force a replacement.
+func TestReplaceOnChanges(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "replace_on_changes_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	t.Run("PatchableResource", func(t *testing.T) {
+		resourceToken := "fake-package:appliances/v2:Appliance"
+		resourceSpec, ok := testPulumiPkg.Resources[resourceToken]
+		assert.Truef(t, ok, "Expected to find a resource called Appliance: %v", testPulumiPkg.Resources)
+
+		assert.Equal(t, []string{"config.b", "name", "region"}, metadata.ReplaceOnChangesMap[resourceToken])
+		assert.Equal(t, []string{"config", "size"}, metadata.PatchablePropertiesMap[resourceToken])
+
+		assert.True(t, resourceSpec.InputProperties["name"].ReplaceOnChanges)
+		assert.True(t, resourceSpec.InputProperties["region"].ReplaceOnChanges)
+		assert.True(t, resourceSpec.Properties["name"].ReplaceOnChanges)
+		assert.False(t, resourceSpec.InputProperties["size"].ReplaceOnChanges)
+		assert.False(t, resourceSpec.InputProperties["config"].ReplaceOnChanges)
+	})
+
+	t.Run("NoUpdateEndpoint", func(t *testing.T) {
+		resourceToken := "fake-package:lockers/v2:Locker"
+		resourceSpec := testPulumiPkg.Resources[resourceToken]
+
+		assert.Equal(t, []string{"name", "size"}, metadata.ReplaceOnChangesMap[resourceToken])
+		assert.NotContains(t, metadata.PatchablePropertiesMap, resourceToken)
+		assert.True(t, resourceSpec.InputProperties["name"].ReplaceOnChanges)
+		assert.True(t, resourceSpec.InputProperties["size"].ReplaceOnChanges)
+	})
+
+	t.Run("PutUpdateEndpoint", func(t *testing.T) {
+		resourceToken := "fake-package:kiosks/v2:Kiosk"
+		resourceSpec := testPulumiPkg.Resources[resourceToken]
+
+		assert.NotContains(t, metadata.ReplaceOnChangesMap, resourceToken)
+		assert.False(t, resourceSpec.InputProperties["name"].ReplaceOnChanges)
+		assert.False(t, resourceSpec.InputProperties["location"].ReplaceOnChanges)
+	})
+}
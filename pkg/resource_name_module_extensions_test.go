@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestResourceNameAndModuleExtensions tests that the x-pulumi-resource-name
+// and x-pulumi-module operation extensions take precedence over the
+// resource name and module derived from the operationId and the path.
+func TestResourceNameAndModuleExtensions(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "resource_name_module_extensions_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	resourceToken := "fake-package:things:Widget"
+	_, ok := testPulumiPkg.Resources[resourceToken]
+	assert.Truef(t, ok, "Expected to find a resource called Widget in the module things: %v", testPulumiPkg.Resources)
+	assert.NotContains(t, testPulumiPkg.Resources, "fake-package:gizmos/v2:Gizmo")
+
+	crudMap, ok := metadata.ResourceCRUDMap[resourceToken]
+	assert.Truef(t, ok, "Expected to find a CRUD map entry for Widget")
+	assert.Equal(t, "/v2/gizmos", *crudMap.C)
+	assert.Equal(t, "/v2/gizmos/{id}", *crudMap.R)
+	assert.Equal(t, "/v2/gizmos/{id}", *crudMap.U)
+	assert.Equal(t, "/v2/gizmos/{id}", *crudMap.D)
+
+	assert.Contains(t, testPulumiPkg.Functions, "fake-package:things:getWidget")
+	assert.Contains(t, testPulumiPkg.Functions, "fake-package:things:listWidgets")
+	assert.Equal(t, "Things", csharpNamespaces["things"])
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		// The invalid module is reported and the module
+		// derived from the path is used instead.
+		assert.Contains(t, testPulumiPkg.Resources, "fake-package:sprockets/v2:Sprocket")
+		assert.Contains(t, openAPICtx.Diagnostics(), Diagnostic{
+			Severity: SeverityWarning,
+			Code:     CodeInvalidExtension,
+			Pointer:  "/paths/~1v2~1sprockets/post",
+			Message:  "x-pulumi-module must be a non-empty string but got 42",
+		})
+	})
+
+	t.Run("InvalidValueReportedOnce", func(t *testing.T) {
+		// The PUT operation is both the create and the update
+		// operation of the resource, but its invalid extensions
+		// are only reported once.
+		for _, ext := range []string{ExtResourceName, ExtModule} {
+			count := 0
+			for _, d := range openAPICtx.Diagnostics() {
+				if d.Pointer == "/paths/~1v2~1cogs~1{id}/put" && strings.HasPrefix(d.Message, ext+" ") {
+					count++
+				}
+			}
+			assert.Equalf(t, 1, count, "Expected %s to be reported once: %v", ext, openAPICtx.Diagnostics())
+		}
+	})
+}
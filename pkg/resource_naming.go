@@ -155,12 +155,28 @@ func getResourceTitleFromOperationID(originalOperationID, method string, isSepar
 	return resourceTitle
 }
 
-var replaceKeywords = map[string]string{"POST": titleCreate, "Post": titleCreate, "post": keywordCreate, "DELETE": titleDelete, titleDelete: titleDelete, "PUT": titlePut, titlePut: titlePut, titlePatch: titlePatch, "PATCH": titlePatch, "GET": titleGet, titleGet: titleGet}
+// replaceKeywords are the HTTP method names and their replacements
+// in resource titles. Only the first match is replaced, so this is
+// a slice to make the result deterministic.
+var replaceKeywords = [][2]string{
+	{"POST", titleCreate},
+	{"Post", titleCreate},
+	{"post", keywordCreate},
+	{"DELETE", titleDelete},
+	{titleDelete, titleDelete},
+	{"PUT", titlePut},
+	{titlePut, titlePut},
+	{titlePatch, titlePatch},
+	{"PATCH", titlePatch},
+	{"GET", titleGet},
+	{titleGet, titleGet},
+}
 
 func sanitizeResourceTitle(title string) string {
 	titleContainsPostgres := strings.Contains(strings.ToLower(title), nounLowerCasePostgres)
 	titleContainsPosture := strings.Contains(strings.ToLower(title), nounLowerCasePosture)
-	for match, replaceWith := range replaceKeywords {
+	for _, keyword := range replaceKeywords {
+		match, replaceWith := keyword[0], keyword[1]
 		if (titleContainsPostgres || titleContainsPosture) && (match == keywordPost || match == "Post") {
 			continue
 		}
@@ -28,6 +28,42 @@ type CRUDOperationsMap struct {
 
 	// P represents the PUT (overwrite/update) endpoint.
 	P *string `json:"p,omitempty"`
+
+	// Operations describes the operation behind each of
+	// the endpoints above.
+	Operations CRUDOperationDescriptors `json:"operations"`
+}
+
+// CRUDOperationDescriptors holds the descriptor of the operation
+// for each of the endpoints in a CRUDOperationsMap.
+type CRUDOperationDescriptors struct {
+	C *OperationDescriptor `json:"c,omitempty"`
+	R *OperationDescriptor `json:"r,omitempty"`
+	U *OperationDescriptor `json:"u,omitempty"`
+	D *OperationDescriptor `json:"d,omitempty"`
+	P *OperationDescriptor `json:"p,omitempty"`
+}
+
+// OperationDescriptor describes how to invoke an API operation
+// so that a provider doesn't need to look it up in the OpenAPI
+// spec again.
+type OperationDescriptor struct {
+	// Path is the API path of the operation.
+	Path string `json:"path"`
+	// Method is the HTTP method of the operation.
+	Method string `json:"method"`
+	// OperationID is the operationId of the operation.
+	OperationID string `json:"operationId"`
+	// RequestContentType is the content type of the request
+	// body. Empty if the operation doesn't have a request body.
+	RequestContentType string `json:"requestContentType,omitempty"`
+	// SuccessStatusCodes are the 2xx status codes of the operation's
+	// responses, for example, `200`, `202` or `2XX`.
+	SuccessStatusCodes []string `json:"successStatusCodes,omitempty"`
+	// ResponseSchema is the name of the component schema of the
+	// first success response. Empty if the response body is not
+	// a ref to a component schema.
+	ResponseSchema string `json:"responseSchema,omitempty"`
 }
 
 // ProviderMetadata represents metadata used by a provider.
@@ -39,6 +75,11 @@ type ProviderMetadata struct {
 	// AutoNameMap is a map of resource type token and the name
 	// property that can be auto-named by the provider.
 	AutoNameMap map[string]string `json:"autoNameMap"`
+	// AutoNameConstraintsMap is a map of resource type token and the
+	// constraints on the values of its auto-named property, so that
+	// the provider generates names that the API accepts. Only the
+	// auto-named properties with constraints have an entry.
+	AutoNameConstraintsMap map[string]*AutoNameConstraints `json:"autoNameConstraintsMap"`
 
 	// SDKToAPINameMap is a map of a property's name in the Pulumi
 	// schema to its actual API name. Can be nil.
@@ -49,6 +90,221 @@ type ProviderMetadata struct {
 	// PathParamNameMap is a map of a path param's original name to
 	// its Pulumi schema name. Can be nil.
 	PathParamNameMap map[string]string `json:"pathParamNameMap"`
+
+	// ReplaceOnChangesMap is a map of resource type token and the
+	// paths of the input properties, including nested ones, that
+	// force a replacement of the resource when changed. Nested
+	// properties use `.` and array items use `[*]`, for example,
+	// `config.size` or `rules[*].port`.
+	ReplaceOnChangesMap map[string][]string `json:"replaceOnChangesMap"`
+	// PatchablePropertiesMap is a map of resource type token and
+	// the input properties that can be updated in-place using its
+	// PATCH endpoint.
+	PatchablePropertiesMap map[string][]string `json:"patchablePropertiesMap"`
+
+	// LongRunningOperationsMap is a map of resource type token and
+	// the description of how to wait for its async create, update
+	// and delete operations to complete. Only resources with at
+	// least one async operation have an entry.
+	LongRunningOperationsMap map[string]*LongRunningOperations `json:"longRunningOperationsMap"`
+
+	// QueryParamsMap is a map of function type token and a map of
+	// its input names to the names of the query params they must
+	// be sent as.
+	QueryParamsMap map[string]map[string]string `json:"queryParamsMap"`
+	// HeaderParamsMap is a map of resource or function type token
+	// and a map of its input names to the names of the header
+	// params they must be sent as.
+	HeaderParamsMap map[string]map[string]string `json:"headerParamsMap"`
+	// ProviderHeaderParamsMap is a map of provider input names to
+	// the names of the header params they must be sent as with
+	// every request.
+	ProviderHeaderParamsMap map[string]string `json:"providerHeaderParamsMap"`
+
+	// PaginationMap is a map of list function type token and the
+	// pagination scheme of its endpoint. Only paginated endpoints
+	// have an entry.
+	PaginationMap map[string]*Pagination `json:"paginationMap"`
+
+	// WriteOnlyPropertiesMap is a map of resource type token and the
+	// paths of its writeOnly input properties, including nested ones.
+	// The API never returns them, so providers must set their outputs
+	// from the last-known inputs and skip them when diffing the state
+	// read from the API. Paths use the same format as
+	// ReplaceOnChangesMap.
+	WriteOnlyPropertiesMap map[string][]string `json:"writeOnlyPropertiesMap"`
+
+	// BinaryPropertiesMap is a map of resource type token and a map
+	// of the paths of its asset and archive inputs to how their
+	// contents are sent in the create request body. Paths use the
+	// same format as ReplaceOnChangesMap.
+	BinaryPropertiesMap map[string]map[string]*BinaryEncoding `json:"binaryPropertiesMap"`
+
+	// ValidationConstraintsMap is a map of resource type token and a
+	// map of the paths of its input properties, including nested ones,
+	// to their validation constraints. Providers can use them to reject
+	// bad inputs in `Check` before calling the API. Paths use the same
+	// format as ReplaceOnChangesMap.
+	ValidationConstraintsMap map[string]map[string]*ValidationConstraints `json:"validationConstraintsMap"`
+}
+
+// AutoNameConstraints are the constraints on the values of an
+// auto-named property.
+type AutoNameConstraints struct {
+	MinLength *uint64 `json:"minLength,omitempty"`
+	MaxLength *uint64 `json:"maxLength,omitempty"`
+	// Pattern is an ECMA-262 regular expression.
+	Pattern string `json:"pattern,omitempty"`
+	// Charset is the set of characters allowed in the names,
+	// written as the contents of a regex character class,
+	// e.g. `a-z0-9-`.
+	Charset string `json:"charset,omitempty"`
+}
+
+// ValidationConstraints are the JSON Schema validation keywords of a
+// property. Only the constraints set in the OpenAPI doc are set.
+type ValidationConstraints struct {
+	MinLength *uint64 `json:"minLength,omitempty"`
+	MaxLength *uint64 `json:"maxLength,omitempty"`
+	// Pattern is an ECMA-262 regular expression.
+	Pattern string `json:"pattern,omitempty"`
+
+	Minimum *float64 `json:"minimum,omitempty"`
+	// ExclusiveMinimum means that Minimum itself isn't allowed.
+	ExclusiveMinimum bool     `json:"exclusiveMinimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	// ExclusiveMaximum means that Maximum itself isn't allowed.
+	ExclusiveMaximum bool     `json:"exclusiveMaximum,omitempty"`
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
+
+	MinItems    *uint64 `json:"minItems,omitempty"`
+	MaxItems    *uint64 `json:"maxItems,omitempty"`
+	UniqueItems bool    `json:"uniqueItems,omitempty"`
+}
+
+// BinaryEncodingType identifies how the contents of an asset or
+// archive are written into a request body.
+type BinaryEncodingType string
+
+const (
+	// BinaryEncodingTypeRaw is used when the contents are the whole
+	// request body.
+	BinaryEncodingTypeRaw BinaryEncodingType = "raw"
+	// BinaryEncodingTypeMultipart is used when the contents are a
+	// part of a multipart/form-data request body.
+	BinaryEncodingTypeMultipart BinaryEncodingType = "multipart"
+	// BinaryEncodingTypeBase64 is used when the contents are a base64
+	// encoded string in a JSON request body or JSON part.
+	BinaryEncodingTypeBase64 BinaryEncodingType = "base64"
+)
+
+// BinaryEncoding describes how the contents of an asset or archive
+// input are sent to the API, so that providers can stream them.
+type BinaryEncoding struct {
+	Encoding BinaryEncodingType `json:"encoding"`
+	// ContentType is the content type of the body or part with the
+	// contents, or of the contents themselves if they are base64
+	// encoded. Empty if the API doesn't say.
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// PaginationScheme identifies how a list endpoint is paginated.
+type PaginationScheme string
+
+const (
+	// PaginationSchemeNextLink is used by endpoints that return the URL
+	// of the next page in the response body or in a `Link` header.
+	PaginationSchemeNextLink PaginationScheme = "nextLink"
+	// PaginationSchemeCursor is used by endpoints that return an opaque
+	// cursor, or page token, that is sent back as a query param to
+	// get the next page.
+	PaginationSchemeCursor PaginationScheme = "cursor"
+	// PaginationSchemeOffset is used by endpoints that take the number
+	// of items to skip, and optionally the page size, as query params.
+	PaginationSchemeOffset PaginationScheme = "offset"
+	// PaginationSchemeNone disables the pagination detection for an
+	// endpoint when used in OpenAPIContext.Pagination.
+	PaginationSchemeNone PaginationScheme = "none"
+)
+
+// Pagination describes how to fetch all of the pages of a list endpoint.
+// Property paths and param names are the names used by the API, not the
+// SDK names. Nested properties are separated by `.`, and names with a
+// `.` or brackets are quoted in brackets, e.g. `["@odata.nextLink"]`.
+type Pagination struct {
+	Scheme PaginationScheme `json:"scheme"`
+
+	// ItemsProperty is the path of the response property with the
+	// items of a page. Empty if the response body is an array.
+	ItemsProperty string `json:"itemsProperty,omitempty"`
+
+	// NextLinkProperty is the path of the response property with
+	// the URL of the next page. Used by the nextLink scheme.
+	NextLinkProperty string `json:"nextLinkProperty,omitempty"`
+	// NextLinkHeader is the response header with the URL of the
+	// next page. Used by the nextLink scheme.
+	NextLinkHeader string `json:"nextLinkHeader,omitempty"`
+
+	// CursorProperty is the path of the response property with the
+	// cursor for the next page. Used by the cursor scheme.
+	CursorProperty string `json:"cursorProperty,omitempty"`
+	// CursorParam is the query param the cursor is sent as. Used by
+	// the cursor scheme.
+	CursorParam string `json:"cursorParam,omitempty"`
+
+	// OffsetParam is the query param with the number of items to
+	// skip. Used by the offset scheme.
+	OffsetParam string `json:"offsetParam,omitempty"`
+	// LimitParam is the query param with the page size. Used by the
+	// offset scheme, optionally.
+	LimitParam string `json:"limitParam,omitempty"`
+}
+
+// PollingURLSource identifies where a provider gets the URL to poll
+// for the status of a long-running operation.
+type PollingURLSource string
+
+const (
+	// PollingURLSourceOperationLocationHeader is the `Operation-Location`
+	// response header.
+	PollingURLSourceOperationLocationHeader PollingURLSource = "operationLocationHeader"
+	// PollingURLSourceAzureAsyncOperationHeader is the `Azure-AsyncOperation`
+	// response header.
+	PollingURLSourceAzureAsyncOperationHeader PollingURLSource = "azureAsyncOperationHeader"
+	// PollingURLSourceLocationHeader is the `Location` response header.
+	PollingURLSourceLocationHeader PollingURLSource = "locationHeader"
+	// PollingURLSourceReadEndpoint is the read endpoint of the resource.
+	// The resource itself is polled until its status property has a
+	// terminal value or, for deletes, until it is not found.
+	PollingURLSourceReadEndpoint PollingURLSource = "readEndpoint"
+)
+
+// LongRunningOperation describes how to wait for an async operation.
+type LongRunningOperation struct {
+	// PollingURLSource is where the URL to poll comes from.
+	PollingURLSource PollingURLSource `json:"pollingUrlSource"`
+
+	// SuccessValues are the terminal values of the resource's status
+	// property that mean this operation succeeded. A delete also
+	// succeeds when the resource is not found.
+	SuccessValues []string `json:"successValues,omitempty"`
+	// FailureValues are the terminal values of the resource's status
+	// property that mean this operation failed.
+	FailureValues []string `json:"failureValues,omitempty"`
+}
+
+// LongRunningOperations describes the async operations of a resource.
+type LongRunningOperations struct {
+	C *LongRunningOperation `json:"c,omitempty"`
+	U *LongRunningOperation `json:"u,omitempty"`
+	D *LongRunningOperation `json:"d,omitempty"`
+	P *LongRunningOperation `json:"p,omitempty"`
+
+	// StatusProperty is the path of the output property with the
+	// status of the resource, for example, `status` or
+	// `properties.provisioningState`. Empty if the resource doesn't
+	// have a status property with known terminal values.
+	StatusProperty string `json:"statusProperty,omitempty"`
 }
 
 type resourceContext struct {
@@ -60,6 +316,25 @@ type resourceContext struct {
 	sdkToAPINameMap   map[string]string
 	apiToSDKNameMap   map[string]string
 	pathParamMap      map[string]string
+	diagnostics       *diagnosticsCollector
+	// pointer is the JSON pointer to the OpenAPI operation
+	// being converted.
+	pointer string
+
+	typeCollisionStrategy TypeCollisionStrategy
+	// renamedTypes is a map of the tokens derived from the names
+	// of component schemas to the tokens that their types were
+	// added under because of a collision.
+	renamedTypes map[string]string
+	// registeredTypes are the tokens of the object types added
+	// to the package using the context, in the order they were
+	// added.
+	registeredTypes []string
+	// binaryAsAssets is true if the schemas being converted are
+	// request schemas, whose files are mapped to assets and
+	// archives. Files in other schemas, including the component
+	// schemas referenced by request schemas, are strings.
+	binaryAsAssets bool
 }
 
 func rawMessage(v interface{}) pschema.RawMessage {
@@ -0,0 +1,83 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/yaml"
+	"github.com/pkg/errors"
+)
+
+// LoadSwaggerDoc reads the Swagger 2.0 doc at path and converts it
+// to an OpenAPI 3 doc.
+func LoadSwaggerDoc(path string) (*openapi3.T, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading swagger spec %s", path)
+	}
+
+	doc, err := ParseSwaggerDoc(b)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading swagger spec %s", path)
+	}
+
+	return doc, nil
+}
+
+// ParseSwaggerDoc parses a YAML or JSON Swagger 2.0 doc and converts
+// it to an OpenAPI 3 doc.
+func ParseSwaggerDoc(b []byte) (*openapi3.T, error) {
+	jsonBytes, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing swagger spec")
+	}
+
+	var doc2 openapi2.T
+	if err := json.Unmarshal(jsonBytes, &doc2); err != nil {
+		return nil, errors.Wrap(err, "decoding swagger spec")
+	}
+
+	return ConvertSwaggerDoc(&doc2)
+}
+
+// ConvertSwaggerDoc converts a Swagger 2.0 doc to an OpenAPI 3 doc
+// and validates the result.
+//
+// `definitions` become `components.schemas`, `body` and `formData`
+// params become request bodies, and the `x-` extensions of the doc,
+// paths, operations, params, responses and schemas are carried over,
+// so that the extensions supported by the conversion, such as
+// ExtMSLongRunningOperation, work the same way for both versions.
+func ConvertSwaggerDoc(doc2 *openapi2.T) (*openapi3.T, error) {
+	if doc2.Swagger != "2.0" {
+		return nil, errors.Errorf("unsupported swagger version %q (must be 2.0)", doc2.Swagger)
+	}
+
+	doc, err := openapi2conv.ToV3(doc2)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting swagger spec to openapi 3")
+	}
+
+	if err := doc.Validate(context.Background(), openapi3.DisableExamplesValidation()); err != nil {
+		return nil, errors.Wrap(err, "validating converted swagger spec")
+	}
+
+	return doc, nil
+}
+
+// NewOpenAPIContextFromSwagger converts the Swagger 2.0 doc and returns
+// an OpenAPIContext for converting it with the options from the config.
+func (c *Config) NewOpenAPIContextFromSwagger(doc2 *openapi2.T) (*OpenAPIContext, error) {
+	doc, err := ConvertSwaggerDoc(doc2)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewOpenAPIContext(doc)
+}
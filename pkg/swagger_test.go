@@ -0,0 +1,48 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSwaggerDoc tests that a Swagger 2.0 doc is converted with
+// its extensions.
+func TestSwaggerDoc(t *testing.T) {
+	doc, err := LoadSwaggerDoc(filepath.Join("testdata", "swagger_openapi.yml"))
+	assert.Nil(t, err)
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *doc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	resourceToken := "fake-package:toasters/v2:Toaster"
+	resourceSpec, ok := testPulumiPkg.Resources[resourceToken]
+	assert.Truef(t, ok, "Expected to find a resource called Toaster: %v", testPulumiPkg.Resources)
+
+	assert.Contains(t, resourceSpec.InputProperties, "name")
+	assert.Equal(t, "application/json", metadata.ResourceCRUDMap[resourceToken].Operations.C.RequestContentType)
+
+	t.Run("Extensions", func(t *testing.T) {
+		assert.True(t, resourceSpec.InputProperties["apiToken"].Secret)
+
+		lro, ok := metadata.LongRunningOperationsMap[resourceToken]
+		assert.Truef(t, ok, "Expected the create operation to be async: %v", metadata.LongRunningOperationsMap)
+		assert.Equal(t, PollingURLSourceReadEndpoint, lro.C.PollingURLSource)
+	})
+}
+
+func TestConvertSwaggerDocRejectsOtherVersions(t *testing.T) {
+	_, err := ConvertSwaggerDoc(&openapi2.T{Swagger: "1.2"})
+	assert.EqualError(t, err, `unsupported swagger version "1.2" (must be 2.0)`)
+}
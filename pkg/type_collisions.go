@@ -0,0 +1,102 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/golang/glog"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// TypeCollisionStrategy identifies how an object type is named when
+// another type with a different spec already has its token.
+type TypeCollisionStrategy string
+
+const (
+	// TypeCollisionStrategyPrefix prefixes the name of the type with the
+	// name of the resource it belongs to, like enum types are. The counter
+	// strategy is used if the prefixed name collides as well, or if the
+	// type doesn't belong to a resource. This is the default.
+	TypeCollisionStrategyPrefix TypeCollisionStrategy = "prefix"
+	// TypeCollisionStrategyCounter suffixes the name of the type with the
+	// first number, starting from 2, that makes the token unique.
+	TypeCollisionStrategyCounter TypeCollisionStrategy = "counter"
+	// TypeCollisionStrategyError reports the collision as an error
+	// diagnostic and keeps the earlier type.
+	TypeCollisionStrategyError TypeCollisionStrategy = "error"
+)
+
+// registerObjectType adds an object type spec to the package under the
+// token for typName in the module of the context. If another type with a
+// different spec already has that token, the type is added under the token
+// picked by the TypeCollisionStrategy instead. Returns the token of the
+// type and whether the type was added, which is false if a type with the
+// same spec already had the token.
+func (ctx *resourceContext) registerObjectType(typName string, spec pschema.ComplexTypeSpec) (string, bool) {
+	tokenFor := func(name string) string {
+		return fmt.Sprintf("%s:%s:%s", ctx.pkg.Name, ctx.mod, name)
+	}
+
+	// tryRegister adds the type under the token for name unless
+	// a different type has it, and returns true if the type has
+	// the token.
+	var added bool
+	tryRegister := func(name string) bool {
+		other, ok := ctx.pkg.Types[tokenFor(name)]
+		if !ok {
+			ctx.pkg.Types[tokenFor(name)] = spec
+			ctx.registeredTypes = append(ctx.registeredTypes, tokenFor(name))
+			added = true
+			return true
+		}
+		return sameSpec(other, spec)
+	}
+
+	tok := tokenFor(typName)
+	if tryRegister(typName) {
+		return tok, added
+	}
+
+	switch ctx.typeCollisionStrategy {
+	case TypeCollisionStrategyError:
+		// The request and response bodies of an operation often
+		// have the same property, so only report it once.
+		reported := slices.ContainsFunc(ctx.diagnostics.diags, func(d Diagnostic) bool {
+			return d.Code == CodeTypeCollision && d.Pointer == ctx.pointer && d.Token == tok
+		})
+		if !reported {
+			ctx.diagnostics.errorf(CodeTypeCollision, ctx.pointer, tok, "the type is already generated with a different spec")
+		}
+		return tok, false
+	case TypeCollisionStrategyCounter:
+	default:
+		if ctx.resourceName != "" && !strings.HasPrefix(typName, ctx.resourceName) && tryRegister(ctx.resourceName+typName) {
+			glog.V(2).Infof("Type %s collides with another type, using %s", tok, tokenFor(ctx.resourceName+typName))
+			return tokenFor(ctx.resourceName + typName), added
+		}
+	}
+
+	for i := 2; ; i++ {
+		name := fmt.Sprintf("%s%d", typName, i)
+		if tryRegister(name) {
+			glog.V(2).Infof("Type %s collides with another type, using %s", tok, tokenFor(name))
+			return tokenFor(name), added
+		}
+	}
+}
+
+// rewriteRenamedTypeRefs rewrites the refs to oldTok in the object types
+// registered using the context since the first registered index, to refs
+// to newTok. The types of a recursive schema reference the schema's type
+// before it is registered, so the refs have to follow its token if it
+// collides with another type and is renamed.
+func (ctx *resourceContext) rewriteRenamedTypeRefs(firstRegistered int, oldTok, newTok string) {
+	renames := map[string]string{oldTok: newTok}
+	for _, tok := range ctx.registeredTypes[firstRegistered:] {
+		rewritePropertyTypeRefs(ctx.pkg.Types[tok].Properties, renames)
+	}
+}
@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// gatherTypeCollisionsTestPackage converts the type collisions test doc
+// specFile into a new package using strategy.
+func gatherTypeCollisionsTestPackage(t *testing.T, specFile string, strategy TypeCollisionStrategy) (*OpenAPIContext, error) {
+	doc, err := openapi3.NewLoader().LoadFromFile(filepath.Join("testdata", specFile))
+	require.NoError(t, err)
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *doc,
+		Pkg: &pschema.PackageSpec{
+			Name:      packageName,
+			Types:     map[string]pschema.ComplexTypeSpec{},
+			Resources: map[string]pschema.ResourceSpec{},
+			Functions: map[string]pschema.FunctionSpec{},
+			Language:  map[string]pschema.RawMessage{},
+		},
+		TypeCollisionStrategy: strategy,
+	}
+
+	_, _, err = openAPICtx.GatherResourcesFromAPI(map[string]string{"": providerNamespace})
+	return openAPICtx, err
+}
+
+// getInputRef returns the type ref of an input property of a resource
+// in the crates module.
+func getInputRef(pkg *pschema.PackageSpec, resourceName, propName string) string {
+	return pkg.Resources[packageName+":crates/v2:"+resourceName].InputProperties[propName].Ref
+}
+
+func TestTypeCollisionStrategyPrefix(t *testing.T) {
+	openAPICtx, err := gatherTypeCollisionsTestPackage(t, "type_collisions_openapi.yml", "")
+	require.NoError(t, err)
+
+	pkg := openAPICtx.Pkg
+	assert.Equal(t, "#/types/fake-package:crates/v2:LidProperties", getInputRef(pkg, "Crate", "lid"))
+	assert.Equal(t, "#/types/fake-package:crates/v2:BinLidProperties", getInputRef(pkg, "Bin", "lid"))
+	assert.Equal(t, "#/types/fake-package:crates/v2:TrayLidProperties", getInputRef(pkg, "Tray", "cover"))
+	assert.Equal(t, "#/types/fake-package:crates/v2:TrayLidProperties", pkg.Resources[packageName+":crates/v2:Tray"].Properties["cover"].Ref)
+
+	assert.Contains(t, pkg.Types[packageName+":crates/v2:LidProperties"].Properties, "width")
+	assert.Contains(t, pkg.Types[packageName+":crates/v2:BinLidProperties"].Properties, "color")
+	assert.Contains(t, pkg.Types[packageName+":crates/v2:TrayLidProperties"].Properties, "depth")
+}
+
+func TestTypeCollisionStrategyCounter(t *testing.T) {
+	openAPICtx, err := gatherTypeCollisionsTestPackage(t, "type_collisions_openapi.yml", TypeCollisionStrategyCounter)
+	require.NoError(t, err)
+
+	pkg := openAPICtx.Pkg
+	assert.Equal(t, "#/types/fake-package:crates/v2:LidProperties", getInputRef(pkg, "Crate", "lid"))
+	assert.Equal(t, "#/types/fake-package:crates/v2:LidProperties2", getInputRef(pkg, "Tray", "cover"))
+	assert.Equal(t, "#/types/fake-package:crates/v2:LidProperties3", getInputRef(pkg, "Bin", "lid"))
+	assert.Len(t, pkg.Types, 3)
+}
+
+func TestTypeCollisionStrategyError(t *testing.T) {
+	openAPICtx, err := gatherTypeCollisionsTestPackage(t, "type_collisions_openapi.yml", TypeCollisionStrategyError)
+	require.Error(t, err)
+
+	var collisions Diagnostics
+	for _, diag := range openAPICtx.Diagnostics() {
+		if diag.Code == CodeTypeCollision {
+			collisions = append(collisions, diag)
+		}
+	}
+	if assert.Len(t, collisions, 2) {
+		assert.Equal(t, packageName+":crates/v2:LidProperties", collisions[0].Token)
+		assert.Equal(t, "/paths/~1v2~1crates~1{crate_id}~1trays/post", collisions[0].Pointer)
+		assert.Equal(t, "/paths/~1v2~1crates~1{crate_id}~1bins/post", collisions[1].Pointer)
+	}
+	assert.Len(t, openAPICtx.Pkg.Types, 1)
+}
+
+// TestTypeCollisionRecursiveSchema tests that the refs of a recursive
+// schema to itself use the token its type is renamed to.
+func TestTypeCollisionRecursiveSchema(t *testing.T) {
+	openAPICtx, err := gatherTypeCollisionsTestPackage(t, "type_collisions_recursive_openapi.yml", "")
+	require.NoError(t, err)
+
+	pkg := openAPICtx.Pkg
+	assert.Equal(t, "#/types/fake-package:crates/v2:ShelfProperties", getInputRef(pkg, "Crate", "shelf"))
+	assert.Equal(t, "#/types/fake-package:crates/v2:RackShelfProperties", getInputRef(pkg, "Rack", "shelf"))
+
+	shelfType := pkg.Types[packageName+":crates/v2:RackShelfProperties"]
+	if assert.NotNil(t, shelfType.Properties["shelves"].Items) {
+		assert.Equal(t, "#/types/fake-package:crates/v2:RackShelfProperties", shelfType.Properties["shelves"].Items.Ref)
+	}
+	assert.Contains(t, pkg.Types[packageName+":crates/v2:ShelfProperties"].Properties, "height")
+	assert.Len(t, pkg.Types, 2)
+}
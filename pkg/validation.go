@@ -0,0 +1,90 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// gatherValidationConstraints records the validation constraints of the
+// input properties of a resource's request body schema, including nested
+// ones, so that providers can reject bad inputs in `Check`.
+func (o *OpenAPIContext) gatherValidationConstraints(typeToken string, requestBodySchema *openapi3.Schema, inputProperties map[string]pschema.PropertySpec) {
+	for name, prop := range getAPISchemaProperties(requestBodySchema) {
+		sdkName := ToSdkName(name)
+		if _, ok := inputProperties[sdkName]; !ok {
+			continue
+		}
+
+		o.addValidationConstraints(typeToken, sdkName, prop.Value, map[*openapi3.Schema]bool{})
+	}
+}
+
+// addValidationConstraints records the validation constraints of the
+// schema at path and of its array items and properties. Array items
+// are denoted with `[*]` in the paths.
+func (o *OpenAPIContext) addValidationConstraints(typeToken, path string, schema *openapi3.Schema, visited map[*openapi3.Schema]bool) {
+	if schema == nil || schema.ReadOnly || visited[schema] {
+		return
+	}
+	visited[schema] = true
+	defer delete(visited, schema)
+
+	if c := getValidationConstraints(schema); c != nil {
+		if _, ok := o.validationConstraintsMap[typeToken]; !ok {
+			o.validationConstraintsMap[typeToken] = make(map[string]*ValidationConstraints)
+		}
+		o.validationConstraintsMap[typeToken][path] = c
+	}
+
+	if schema.Items != nil {
+		o.addValidationConstraints(typeToken, path+"[*]", schema.Items.Value, visited)
+	}
+
+	for name, prop := range getAPISchemaProperties(schema) {
+		o.addValidationConstraints(typeToken, path+"."+ToSdkName(name), prop.Value, visited)
+	}
+}
+
+// getValidationConstraints returns the validation constraints of a
+// schema, or nil if it doesn't have any. The OpenAPI 3.1 numeric
+// `exclusiveMinimum` and `exclusiveMaximum` are returned as an
+// exclusive `minimum` and `maximum`.
+func getValidationConstraints(s *openapi3.Schema) *ValidationConstraints {
+	c := &ValidationConstraints{
+		MaxLength:   s.MaxLength,
+		Pattern:     s.Pattern,
+		Minimum:     s.Min,
+		Maximum:     s.Max,
+		MultipleOf:  s.MultipleOf,
+		MaxItems:    s.MaxItems,
+		UniqueItems: s.UniqueItems,
+	}
+	if s.MinLength > 0 {
+		c.MinLength = &s.MinLength
+	}
+	if s.MinItems > 0 {
+		c.MinItems = &s.MinItems
+	}
+
+	if v := s.ExclusiveMin.Value; v != nil && (c.Minimum == nil || *v >= *c.Minimum) {
+		c.Minimum = v
+		c.ExclusiveMinimum = true
+	} else if c.Minimum != nil {
+		c.ExclusiveMinimum = s.ExclusiveMin.IsTrue()
+	}
+	if v := s.ExclusiveMax.Value; v != nil && (c.Maximum == nil || *v <= *c.Maximum) {
+		c.Maximum = v
+		c.ExclusiveMaximum = true
+	} else if c.Maximum != nil {
+		c.ExclusiveMaximum = s.ExclusiveMax.IsTrue()
+	}
+
+	if *c == (ValidationConstraints{}) {
+		return nil
+	}
+
+	return c
+}
@@ -0,0 +1,56 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidationConstraints tests that the validation constraints of
+// resource inputs are listed in the metadata.
+func TestValidationConstraints(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "validation_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	uint64Ptr := func(v uint64) *uint64 { return &v }
+	float64Ptr := func(v float64) *float64 { return &v }
+
+	assert.Equal(t, map[string]*ValidationConstraints{
+		"marinaId":     {Pattern: "^mr-[0-9]+$"},
+		"name":         {MinLength: uint64Ptr(1), MaxLength: uint64Ptr(64), Pattern: "^[a-z][a-z0-9-]*$"},
+		"length":       {Minimum: float64Ptr(2), Maximum: float64Ptr(100), ExclusiveMaximum: true, MultipleOf: float64Ptr(0.5)},
+		"tags":         {MinItems: uint64Ptr(1), MaxItems: uint64Ptr(10), UniqueItems: true},
+		"tags[*]":      {MaxLength: uint64Ptr(20)},
+		"engine.power": {Minimum: float64Ptr(0)},
+	}, metadata.ValidationConstraintsMap["fake-package:marinas/v2:Yacht"])
+}
+
+func TestGetValidationConstraintsExclusiveBounds(t *testing.T) {
+	lowerBound, upperBound := 1.0, 10.0
+	s := &openapi3.Schema{
+		ExclusiveMin: openapi3.ExclusiveBound{Value: &lowerBound},
+		ExclusiveMax: openapi3.ExclusiveBound{Value: &upperBound},
+	}
+
+	assert.Equal(t, &ValidationConstraints{
+		Minimum:          &lowerBound,
+		ExclusiveMinimum: true,
+		Maximum:          &upperBound,
+		ExclusiveMaximum: true,
+	}, getValidationConstraints(s))
+
+	assert.Nil(t, getValidationConstraints(&openapi3.Schema{}))
+}
@@ -0,0 +1,93 @@
+// Copyright 2022, Cloudy Sky Software.
+
+package pkg
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// gatherWriteOnlyProperties records the paths of the writeOnly properties
+// of a resource's request body schema. writeOnly properties stay inputs
+// and outputs, since their outputs are set from the last-known inputs.
+// They are marked as secrets by isSecretProperty. Top-level writeOnly
+// properties that are only in the response body schema are removed from
+// the outputs since the API never returns them.
+func (o *OpenAPIContext) gatherWriteOnlyProperties(typeToken string, requestBodySchema, responseBodySchema *openapi3.Schema, inputProperties, properties map[string]pschema.PropertySpec, requiredOutputs codegen.StringSet) {
+	if responseBodySchema != nil {
+		for name, prop := range getAPISchemaProperties(responseBodySchema) {
+			sdkName := ToSdkName(name)
+			if _, ok := inputProperties[sdkName]; ok || prop.Value == nil || !prop.Value.WriteOnly {
+				continue
+			}
+
+			delete(properties, sdkName)
+			requiredOutputs.Delete(sdkName)
+		}
+	}
+
+	paths := getWriteOnlyPaths("", requestBodySchema, map[*openapi3.Schema]bool{})
+	paths = slices.DeleteFunc(paths, func(p string) bool {
+		topLevelName, _, _ := strings.Cut(p, ".")
+		_, ok := inputProperties[strings.TrimSuffix(topLevelName, "[*]")]
+		return !ok
+	})
+	if len(paths) == 0 {
+		return
+	}
+
+	slices.Sort(paths)
+	o.writeOnlyPropertiesMap[typeToken] = paths
+}
+
+// isSecretProperty returns true if the property is marked as a secret
+// with the ExtSecretProp extension or, without the extension, if it is
+// writeOnly. writeOnly properties, such as passwords, are usually
+// sensitive, and their outputs hold the last-known inputs.
+func isSecretProperty(schema *openapi3.Schema) bool {
+	if isSecret, ok := schema.Extensions[ExtSecretProp]; ok {
+		return isSecret.(bool)
+	}
+
+	return schema.WriteOnly
+}
+
+// getWriteOnlyPaths returns the paths of the writeOnly properties
+// of a schema, including nested ones, using their SDK names. Array
+// items are denoted with `[*]` in the paths.
+func getWriteOnlyPaths(prefix string, schema *openapi3.Schema, visited map[*openapi3.Schema]bool) []string {
+	if schema == nil || visited[schema] {
+		return nil
+	}
+	visited[schema] = true
+	defer delete(visited, schema)
+
+	var paths []string
+	props := getAPISchemaProperties(schema)
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		prop := props[name].Value
+		if prop == nil {
+			continue
+		}
+
+		path := prefix + ToSdkName(name)
+		if prop.WriteOnly {
+			paths = append(paths, path)
+			continue
+		}
+
+		if prop.Items != nil {
+			prop = prop.Items.Value
+			path += "[*]"
+		}
+		paths = append(paths, getWriteOnlyPaths(path+".", prop, visited)...)
+	}
+
+	return paths
+}
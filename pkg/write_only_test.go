@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWriteOnlyProperties tests that writeOnly properties stay inputs,
+// are marked as secrets and are listed in the metadata.
+func TestWriteOnlyProperties(t *testing.T) {
+	mustReadTestOpenAPIDoc(t, filepath.Join("testdata", "write_only_openapi.yml"))
+
+	openAPICtx := &OpenAPIContext{
+		Doc: *testOpenAPIDoc,
+		Pkg: &testPulumiPkg,
+	}
+
+	csharpNamespaces := map[string]string{
+		"": providerNamespace,
+	}
+
+	metadata, _, err := openAPICtx.GatherResourcesFromAPI(csharpNamespaces)
+	assert.Nil(t, err)
+
+	resourceToken := "fake-package:accounts/v2:Account"
+	resourceSpec, ok := testPulumiPkg.Resources[resourceToken]
+	assert.Truef(t, ok, "Expected to find a resource called Account: %v", testPulumiPkg.Resources)
+
+	assert.Equal(t, []string{
+		"clientSecret",
+		"credentials[*].token",
+		"password",
+		"recoveryCode",
+	}, metadata.WriteOnlyPropertiesMap[resourceToken])
+
+	for _, name := range []string{"password", "clientSecret"} {
+		assert.Truef(t, resourceSpec.InputProperties[name].Secret, "Expected input %s to be a secret", name)
+		assert.Truef(t, resourceSpec.Properties[name].Secret, "Expected output %s to be a secret", name)
+	}
+	assert.Contains(t, resourceSpec.RequiredInputs, "password")
+	assert.False(t, resourceSpec.InputProperties["email"].Secret)
+
+	t.Run("SecretExtensionOptOut", func(t *testing.T) {
+		assert.False(t, resourceSpec.InputProperties["recoveryCode"].Secret)
+	})
+
+	t.Run("NestedProperty", func(t *testing.T) {
+		credentialType := testPulumiPkg.Types["fake-package:accounts/v2:AccountCredential"]
+		assert.True(t, credentialType.Properties["token"].Secret)
+		assert.False(t, credentialType.Properties["user"].Secret)
+	})
+
+	t.Run("ResponseOnlyProperty", func(t *testing.T) {
+		assert.NotContains(t, resourceSpec.Properties, "resetToken")
+	})
+}